@@ -0,0 +1,172 @@
+package sortedmap
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Backend is an ordered storage of key-value pairs behind a SortedMap.
+//
+// A backend doesn't look pairs up by key: SortedMap keeps the keys itself, never inserts a key
+// which is already stored, and always passes the stored pair to Delete, so the backend can find it by order.
+// The order of pairs which are equal according to the comparison function is up to the backend.
+type Backend[K comparable, V any] interface {
+	// Len returns the number of stored pairs.
+	Len() int
+	// Insert stores the pair.
+	Insert(kv KV[K, V])
+	// Delete removes the stored pair with the same key as kv and reports whether it was found.
+	Delete(kv KV[K, V]) bool
+	// All returns a sequence of the stored pairs in ascending order.
+	All() iter.Seq[KV[K, V]]
+	// Backward returns a sequence of the stored pairs in descending order.
+	Backward() iter.Seq[KV[K, V]]
+	// Seek returns a sequence of the stored pairs in ascending order,
+	// starting from the first one which is not less than probe.
+	Seek(probe KV[K, V]) iter.Seq[KV[K, V]]
+	// SeekBackward returns a sequence of the stored pairs in descending order,
+	// starting from the last one which is not greater than probe.
+	SeekBackward(probe KV[K, V]) iter.Seq[KV[K, V]]
+	// AllFrom returns a sequence of the stored pairs in ascending order, starting from the i-th one.
+	AllFrom(i int) iter.Seq[KV[K, V]]
+	// Rank returns the position of the stored pair with the same key as kv, or -1 if there is no such pair.
+	Rank(kv KV[K, V]) int
+	// At returns the i-th pair in the order and false if there is no such pair.
+	At(i int) (KV[K, V], bool)
+	// Min returns the first pair in the order and false if there are no pairs.
+	Min() (KV[K, V], bool)
+	// Max returns the last pair in the order and false if there are no pairs.
+	Max() (KV[K, V], bool)
+}
+
+// Storage is a kind of built-in Backend.
+type Storage int
+
+// Built-in storages.
+//
+//	Storage     | Insert, Delete | All, Backward | Seek, SeekBackward (first pair) | Min      | Max
+//	------------|----------------|---------------|---------------------------------|----------|-------------
+//	Tree        | O(log n)       | O(n)          | O(log n)                        | O(log n) | O(log n)
+//	Heap        | O(log n)       | O(n log n)    | O(n log n)                      | O(1)     | O(n)
+//	BTree       | O(log n)       | O(n)          | O(log n)                        | O(log n) | O(log n)
+//	SkipList    | O(log n) avg   | O(n)          | O(log n) avg                    | O(1)     | O(log n) avg
+//	SortedSlice | O(n)           | O(n)          | O(log n)                        | O(1)     | O(1)
+//
+// Rank, At and AllFrom take O(log n) for Tree and SortedSlice, O(n) for BTree and SkipList,
+// and O(n log n) for Heap.
+const (
+	// Tree is an AVL tree, a good default for mixed workloads.
+	Tree Storage = iota
+	// Heap is a binary heap. It's the cheapest one to write and to get the minimum from,
+	// but every ordered traversal has to sort the pairs.
+	Heap
+	// BTree is a B-tree. It's more cache friendly than Tree for large maps.
+	BTree
+	// SkipList is a skip list. It needs no rebalancing, so writes are cheap and predictable.
+	SkipList
+	// SortedSlice is a plain slice kept in order. It's the fastest one to scan and the most compact,
+	// but every write shifts the tail of the slice.
+	SortedSlice
+)
+
+func (s Storage) String() string {
+	switch s {
+	case Tree:
+		return "Tree"
+	case Heap:
+		return "Heap"
+	case BTree:
+		return "BTree"
+	case SkipList:
+		return "SkipList"
+	case SortedSlice:
+		return "SortedSlice"
+	}
+
+	return fmt.Sprintf("Storage(%d)", int(s))
+}
+
+// NewBackend creates a built-in backend of the given storage kind with `less` as the comparison function.
+func NewBackend[K comparable, V any](s Storage, less func(i, j KV[K, V]) bool) Backend[K, V] {
+	if less == nil {
+		panic("less function is required")
+	}
+
+	switch s {
+	case Tree:
+		return newTree(less, sameKey[K, V])
+	case Heap:
+		return newKvHeap(less)
+	case BTree:
+		return newBTree(less, sameKey[K, V])
+	case SkipList:
+		return newSkipList(less, sameKey[K, V])
+	case SortedSlice:
+		return newSortedSlice(less, sameKey[K, V])
+	}
+
+	panic(fmt.Sprintf("unknown storage: %v", s))
+}
+
+// loader is implemented by the built-in backends which replace their content with sorted items in O(n).
+type loader[T any] interface {
+	load(items []T)
+}
+
+// heapifier is implemented by the built-in backends which are built from items in any order in O(n).
+type heapifier[T any] interface {
+	heapify(items []T)
+}
+
+// loadSorted inserts the pairs, which have to be in ascending order, into the empty backend. The built-in storages
+// build themselves from sorted pairs in O(n), other backends get the pairs one by one.
+func loadSorted[K comparable, V any](b Backend[K, V], pairs []KV[K, V]) {
+	if l, ok := b.(loader[KV[K, V]]); ok {
+		l.load(pairs)
+		return
+	}
+	for _, kv := range pairs {
+		b.Insert(kv)
+	}
+}
+
+// skipItems returns the sequence without its first i items.
+func skipItems[T any](seq iter.Seq[T], i int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		j := 0
+		for item := range seq {
+			if j++; j > i && !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// rankOf returns the position of the item in the sequence which is the same as the given one, or -1.
+func rankOf[T any](seq iter.Seq[T], item T, same func(a, b T) bool) int {
+	i := 0
+	for x := range seq {
+		if same(item, x) {
+			return i
+		}
+		i++
+	}
+
+	return -1
+}
+
+// itemAt returns the i-th item of the sequence and false if there is no such item.
+func itemAt[T any](seq iter.Seq[T], i int) (T, bool) {
+	if i >= 0 {
+		for x := range skipItems(seq, i) {
+			return x, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func sameKey[K comparable, V any](i, j KV[K, V]) bool {
+	return i.Key == j.Key
+}
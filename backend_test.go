@@ -0,0 +1,367 @@
+package sortedmap
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var storages = []Storage{Tree, Heap, BTree, SkipList, SortedSlice}
+
+// checkSorted verifies that got is ordered by less and holds the same pairs as want.
+func checkSorted[K comparable, V comparable](t *testing.T, got []KV[K, V], want map[K]V, less func(i, j KV[K, V]) bool) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d pairs, want %d", len(got), len(want))
+	}
+	for i, kv := range got {
+		if v, ok := want[kv.Key]; !ok || v != kv.Val {
+			t.Fatalf("unexpected pair %v at %d", kv, i)
+		}
+		if i > 0 && less(kv, got[i-1]) {
+			t.Fatalf("pairs are not sorted at %d: %v, %v", i, got[i-1], kv)
+		}
+	}
+}
+
+// checkBTree verifies the B-tree invariants and returns the depth of the subtree.
+func checkBTree[T any](t *testing.T, b *btree[T], x *bnode[T], root bool) int {
+	t.Helper()
+	if !root && len(x.items) < btreeDegree-1 || len(x.items) > 2*btreeDegree-1 {
+		t.Fatalf("node has %d items", len(x.items))
+	}
+	for i := 1; i < len(x.items); i++ {
+		if b.less(x.items[i], x.items[i-1]) {
+			t.Fatalf("node items are not sorted: %v", x.items)
+		}
+	}
+	if x.leaf() {
+		return 1
+	}
+	if len(x.children) != len(x.items)+1 {
+		t.Fatalf("node has %d items and %d children", len(x.items), len(x.children))
+	}
+	depth := checkBTree(t, b, x.children[0], false)
+	for _, c := range x.children[1:] {
+		if d := checkBTree(t, b, c, false); d != depth {
+			t.Fatalf("leaves are at different depths: %d and %d", depth, d)
+		}
+	}
+
+	return depth + 1
+}
+
+func TestBackend(t *testing.T) {
+	// values are compared by tens, so there are a lot of equal pairs
+	less := func(i, j KV[int, int]) bool {
+		return i.Val/10 < j.Val/10
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(42))
+			b := NewBackend(s, less)
+			want := map[int]int{}
+			for i := 0; i < 5000; i++ {
+				key := rnd.Intn(500)
+				if v, ok := want[key]; ok {
+					if !b.Delete(KV[int, int]{key, v}) {
+						t.Fatalf("Delete(%v) = false, want true", KV[int, int]{key, v})
+					}
+					delete(want, key)
+				} else {
+					v := rnd.Intn(1000)
+					b.Insert(KV[int, int]{key, v})
+					want[key] = v
+				}
+				if b.Len() != len(want) {
+					t.Fatalf("Len() = %d, want %d", b.Len(), len(want))
+				}
+			}
+			if b.Delete(KV[int, int]{-1, 0}) {
+				t.Errorf("Delete() of a missing pair = true, want false")
+			}
+			if bt, ok := b.(*btree[KV[int, int]]); ok {
+				checkBTree(t, bt, bt.root, true)
+			}
+
+			all := slices.Collect(b.All())
+			checkSorted(t, all, want, less)
+			backward := slices.Collect(b.Backward())
+			slices.Reverse(backward)
+			checkSorted(t, backward, want, less)
+			if kv, ok := b.Min(); !ok || less(kv, all[0]) || less(all[0], kv) {
+				t.Errorf("Min() = %v, %v, want %v", kv, ok, all[0])
+			}
+			if kv, ok := b.Max(); !ok || less(kv, all[len(all)-1]) || less(all[len(all)-1], kv) {
+				t.Errorf("Max() = %v, %v, want %v", kv, ok, all[len(all)-1])
+			}
+			for i, kv := range all {
+				if r := b.Rank(kv); r != i {
+					t.Fatalf("Rank(%v) = %d, want %d", kv, r, i)
+				}
+				if got, ok := b.At(i); !ok || got != kv {
+					t.Fatalf("At(%d) = %v, %v, want %v", i, got, ok, kv)
+				}
+			}
+			if r := b.Rank(KV[int, int]{-1, 0}); r != -1 {
+				t.Errorf("Rank() of a missing pair = %d, want -1", r)
+			}
+			for _, i := range []int{-1, 0, 1, len(all) / 2, len(all) - 1, len(all), len(all) + 1} {
+				if got, want := slices.Collect(b.AllFrom(i)), all[min(max(i, 0), len(all)):]; !slices.Equal(got, want) {
+					t.Fatalf("AllFrom(%d) = %v, want %v", i, got, want)
+				}
+			}
+			if _, ok := b.At(len(all)); ok {
+				t.Errorf("At(%d) = true, want false", len(all))
+			}
+			if _, ok := b.At(-1); ok {
+				t.Errorf("At(-1) = true, want false")
+			}
+			for i := 0; i < 100; i++ {
+				probe := KV[int, int]{Val: rnd.Intn(1100) - 50}
+				tail := maps.Collect(func(yield func(int, int) bool) {
+					for k, v := range want {
+						if !less(KV[int, int]{k, v}, probe) && !yield(k, v) {
+							return
+						}
+					}
+				})
+				checkSorted(t, slices.Collect(b.Seek(probe)), tail, less)
+
+				head := maps.Collect(func(yield func(int, int) bool) {
+					for k, v := range want {
+						if !less(probe, KV[int, int]{k, v}) && !yield(k, v) {
+							return
+						}
+					}
+				})
+				backward := slices.Collect(b.SeekBackward(probe))
+				slices.Reverse(backward)
+				checkSorted(t, backward, head, less)
+			}
+		})
+	}
+}
+
+func TestBackend_empty(t *testing.T) {
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			b := NewBackend(s, func(i, j KV[int, int]) bool { return i.Key < j.Key })
+			b.Insert(KV[int, int]{1, 1})
+			b.Delete(KV[int, int]{1, 1})
+			if kv, ok := b.Min(); ok {
+				t.Errorf("Min() = %v, true, want false", kv)
+			}
+			if kv, ok := b.Max(); ok {
+				t.Errorf("Max() = %v, true, want false", kv)
+			}
+			if got := slices.Collect(b.All()); len(got) != 0 {
+				t.Errorf("All() = %v, want nothing", got)
+			}
+			if got := slices.Collect(b.Backward()); len(got) != 0 {
+				t.Errorf("Backward() = %v, want nothing", got)
+			}
+			if got := slices.Collect(b.SeekBackward(KV[int, int]{1, 1})); len(got) != 0 {
+				t.Errorf("SeekBackward() = %v, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	panicsWithValue(t, "unknown storage: Storage(42)", func() {
+		NewBackend[int, int](Storage(42), func(i, j KV[int, int]) bool { return i.Key < j.Key })
+	})
+	panicsWithValue(t, "less function is required", func() {
+		NewBackend[int, int](Tree, nil)
+	})
+}
+
+// countingBackend is a backend of the user's own: it hides the methods of the built-in storage
+// which are not part of Backend and counts the inserts.
+type countingBackend[K comparable, V any] struct {
+	Backend[K, V]
+	inserts int
+}
+
+func (c *countingBackend[K, V]) Insert(kv KV[K, V]) {
+	c.inserts++
+	c.Backend.Insert(kv)
+}
+
+func TestNewWithBackend(t *testing.T) {
+	less := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	want := map[string]int{"a": 3, "b": 1, "c": 2, "d": 5}
+	src := NewFromMap(want, less)
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	b := &countingBackend[string, int]{Backend: NewBackend(SortedSlice, less)}
+	sm := NewWithBackend[map[string]int](b, less)
+	// the backend can't be built at once, so it gets the pairs one by one
+	if err := sm.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if b.inserts != len(want) {
+		t.Errorf("backend got %d inserts, want %d", b.inserts, len(want))
+	}
+	if !slices.Equal(sm.CollectAll(), src.CollectAll()) {
+		t.Errorf("CollectAll() = %v, want %v", sm.CollectAll(), src.CollectAll())
+	}
+
+	sm.InsertMany(maps.All(map[string]int{"e": 0, "a": 4}))
+	sm.Delete("b")
+	want["e"], want["a"] = 0, 4
+	delete(want, "b")
+	checkSorted(t, sm.CollectAll(), want, less)
+	if k, _, ok := sm.Max(); !ok || k != "d" {
+		t.Errorf("Max() = %v, want d", k)
+	}
+
+	panicsWithValue(t, "backend must be empty", func() {
+		NewWithBackend[map[string]int](b, less)
+	})
+	panicsWithValue(t, "backend must be empty", func() {
+		NewWithBackend[map[string]int, string, int](nil, less)
+	})
+	panicsWithValue(t, "less function is required", func() {
+		NewWithBackend[map[string]int](NewBackend(Tree, less), nil)
+	})
+}
+
+func ExampleWithStorage() {
+	sm := New[map[string]int](func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}, WithStorage(SkipList))
+	sm.Insert("Bob", 42)
+	sm.Insert("Alice", 30)
+	for k, v := range sm.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// Alice 30
+	// Bob 42
+}
+
+func BenchmarkBackend(b *testing.B) {
+	const n = 10_000
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	for _, s := range storages {
+		b.Run(s.String()+"/InsertDelete", func(b *testing.B) {
+			be := NewBackend(s, less)
+			for i := 0; i < n; i++ {
+				be.Insert(KV[int, int]{i, i})
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				kv := KV[int, int]{i % n, i % n}
+				be.Delete(kv)
+				be.Insert(kv)
+			}
+		})
+		b.Run(s.String()+"/All", func(b *testing.B) {
+			be := NewBackend(s, less)
+			for i := 0; i < n; i++ {
+				be.Insert(KV[int, int]{i, i})
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for range be.All() {
+				}
+			}
+		})
+	}
+}
+
+func TestBackend_load(t *testing.T) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Val/10 < j.Val/10
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(42))
+			pairs := make([]KV[int, int], 1000)
+			want := map[int]int{}
+			for i := range pairs {
+				pairs[i] = KV[int, int]{i, rnd.Intn(1000)}
+				want[i] = pairs[i].Val
+			}
+			slices.SortStableFunc(pairs, func(i, j KV[int, int]) int {
+				return i.Val/10 - j.Val/10
+			})
+
+			b := NewBackend(s, less)
+			loadSorted(b, pairs)
+			all := slices.Collect(b.All())
+			checkSorted(t, all, want, less)
+			// the heap orders equal pairs its own way
+			if s != Heap && !slices.Equal(all, pairs) {
+				t.Fatalf("All() = %v, want %v", all, pairs)
+			}
+			if tr, ok := b.(*tree[KV[int, int]]); ok {
+				checkTree(t, tr, tr.root)
+			}
+			if bt, ok := b.(*btree[KV[int, int]]); ok {
+				checkBTree(t, bt, bt.root, true)
+			}
+			for i, kv := range all {
+				if r := b.Rank(kv); r != i {
+					t.Fatalf("Rank(%v) = %d, want %d", kv, r, i)
+				}
+			}
+
+			// the loaded backend keeps working as usual
+			for i := 0; i < 500; i++ {
+				key := rnd.Intn(1000)
+				if !b.Delete(KV[int, int]{key, want[key]}) {
+					t.Fatalf("Delete(%v) = false, want true", KV[int, int]{key, want[key]})
+				}
+				want[key] = rnd.Intn(1000)
+				b.Insert(KV[int, int]{key, want[key]})
+			}
+			checkSorted(t, slices.Collect(b.All()), want, less)
+			backward := slices.Collect(b.Backward())
+			slices.Reverse(backward)
+			checkSorted(t, backward, want, less)
+		})
+	}
+}
+
+func TestBTree_load(t *testing.T) {
+	less := func(a, b int) bool {
+		return a < b
+	}
+	same := func(a, b int) bool {
+		return a == b
+	}
+	// the sizes around the capacity of the trees of height 1, 2 and 3
+	for _, n := range []int{0, 1, 15, 31, 32, 33, 500, 1023, 1024, 1025, 32767, 32768, 40000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			items := make([]int, n)
+			for i := range items {
+				items[i] = i
+			}
+			b := newBTree(less, same)
+			b.load(items)
+			checkBTree(t, b, b.root, true)
+			if got := slices.Collect(b.All()); !slices.Equal(got, items) || b.Len() != n {
+				t.Fatalf("All() has %d items, Len() = %d, want %d in order", len(got), b.Len(), n)
+			}
+
+			b.Insert(n)
+			b.Delete(0)
+			checkBTree(t, b, b.root, true)
+			if b.Len() != n {
+				t.Errorf("Len() = %d, want %d", b.Len(), n)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package sortedmap
+
+import (
+	"iter"
+	"slices"
+)
+
+// batcher is implemented by backends which apply a batch of changes cheaper than pair by pair,
+// like Heap, which restores the heap once, and SortedSlice, which shifts the slice once.
+type batcher[T any] interface {
+	// insertMany stores the items, the slice can be reused.
+	insertMany(items []T)
+	// deleteMany removes the stored items which are the same as the given ones.
+	deleteMany(items []T)
+}
+
+// rebuildRatio is how many times smaller than the map a batch can be to still rebuild a loader backend,
+// like Tree, BTree and SkipList. A smaller batch is applied pair by pair: it takes O(k log n),
+// and a rebuild walks all the pairs.
+const rebuildRatio = 2
+
+// InsertMany adds the key-value pairs of the sequence to the map like a series of Insert calls,
+// and returns the number of keys which were added and the number of pairs which updated a key.
+// The storages apply a batch at once: Heap restores the heap once and SortedSlice shifts the slice once,
+// Tree, BTree and SkipList merge a batch which is large relative to the map with the stored pairs and rebuild.
+// A small batch and the backends of your own get the pairs one by one.
+// The complexity is O(k log(n + k)) for a small batch, O(n + k log k) for a large one, O(n + k) for Heap
+func (sm *SortedMap[Map, K, V]) InsertMany(seq iter.Seq2[K, V]) (added, updated int) {
+	b, ok := sm.b.(batcher[KV[K, V]])
+	l, loads := sm.b.(loader[KV[K, V]])
+	if !ok && !loads {
+		return sm.insertEach(seq)
+	}
+
+	var batch []KV[K, V]
+	for k, v := range seq {
+		batch = append(batch, KV[K, V]{k, v})
+	}
+	if !ok && len(batch)*rebuildRatio < sm.Len() {
+		return sm.insertEach(func(yield func(K, V) bool) {
+			for _, el := range batch {
+				if !yield(el.Key, el.Val) {
+					return
+				}
+			}
+		})
+	}
+
+	// the last pair of a key wins like with Insert, the stored pairs of the updated keys go away
+	inBatch := make(map[K]struct{}, len(batch))
+	var stale []KV[K, V]
+	n := len(batch)
+	for i := len(batch) - 1; i >= 0; i-- {
+		el := batch[i]
+		if _, ok := inBatch[el.Key]; ok {
+			updated++
+			continue
+		}
+		inBatch[el.Key] = struct{}{}
+		if v, ok := sm.m[el.Key]; ok {
+			stale = append(stale, KV[K, V]{el.Key, v})
+			updated++
+		} else {
+			added++
+		}
+		n--
+		batch[n] = el
+	}
+	batch = batch[n:]
+
+	for _, el := range batch {
+		sm.m[el.Key] = el.Val
+	}
+	if !ok {
+		// the equal new pairs keep their order, and go after the equal stored ones, like with Insert
+		order := make([]int, len(batch))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(i, j int) int {
+			if c := sm.cmp(batch[i], batch[j]); c != 0 {
+				return c
+			}
+			return i - j
+		})
+		pairs := make([]KV[K, V], 0, len(sm.m))
+		i := 0
+		for el := range sm.b.All() {
+			if _, ok := inBatch[el.Key]; ok {
+				continue
+			}
+			for ; i < len(order) && sm.less(batch[order[i]], el); i++ {
+				pairs = append(pairs, batch[order[i]])
+			}
+			pairs = append(pairs, el)
+		}
+		for _, j := range order[i:] {
+			pairs = append(pairs, batch[j])
+		}
+		l.load(pairs)
+		return added, updated
+	}
+	if len(stale) > 0 {
+		b.deleteMany(stale)
+	}
+	b.insertMany(batch)
+
+	return added, updated
+}
+
+// insertEach inserts the pairs one by one and counts them like InsertMany.
+func (sm *SortedMap[Map, K, V]) insertEach(seq iter.Seq2[K, V]) (added, updated int) {
+	for k, v := range seq {
+		if _, ok := sm.m[k]; ok {
+			updated++
+		} else {
+			added++
+		}
+		sm.Insert(k, v)
+	}
+
+	return added, updated
+}
+
+// DeleteMany removes the keys of the sequence from the map like a series of Delete calls,
+// and returns the number of keys which were removed.
+// The storages apply a batch at once, see InsertMany.
+// The complexity is O(k log n) for a small batch, O(n + k) for a large one, O(n + k log n) for SortedSlice
+func (sm *SortedMap[Map, K, V]) DeleteMany(seq iter.Seq[K]) (deleted int) {
+	b, ok := sm.b.(batcher[KV[K, V]])
+	l, loads := sm.b.(loader[KV[K, V]])
+	if !ok && !loads {
+		for k := range seq {
+			if _, ok := sm.Delete(k); ok {
+				deleted++
+			}
+		}
+		return deleted
+	}
+
+	var stale []KV[K, V]
+	for k := range seq {
+		if v, ok := sm.m[k]; ok {
+			delete(sm.m, k)
+			stale = append(stale, KV[K, V]{k, v})
+		}
+	}
+	switch {
+	case len(stale) == 0:
+	case ok:
+		b.deleteMany(stale)
+	case len(stale)*rebuildRatio < sm.b.Len():
+		for _, el := range stale {
+			sm.b.Delete(el)
+		}
+	default:
+		pairs := make([]KV[K, V], 0, len(sm.m))
+		for el := range sm.b.All() {
+			if _, ok := sm.m[el.Key]; ok {
+				pairs = append(pairs, el)
+			}
+		}
+		l.load(pairs)
+	}
+
+	return len(stale)
+}
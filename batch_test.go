@@ -0,0 +1,194 @@
+package sortedmap
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSortedMap_InsertMany(t *testing.T) {
+	byVal := func(i, j KV[int, int]) bool {
+		return i.Val < j.Val
+	}
+	tests := []struct {
+		name  string
+		size  int
+		batch int
+	}{
+		{"empty map", 0, 50},
+		{"small batch", 200, 5},
+		{"large batch", 200, 150},
+		{"empty batch", 20, 0},
+	}
+	for _, tt := range tests {
+		for _, s := range storages {
+			t.Run(tt.name+"/"+s.String(), func(t *testing.T) {
+				r := rand.New(rand.NewPCG(1, uint64(tt.size)))
+				sm, want := New[map[int]int](byVal, WithStorage(s)), New[map[int]int](byVal, WithStorage(s))
+				for range tt.size {
+					k, v := r.IntN(tt.size), r.IntN(20)
+					sm.Insert(k, v)
+					want.Insert(k, v)
+				}
+
+				// the keys repeat within the batch and overlap with the map, the values tie
+				var batch []KV[int, int]
+				for range tt.batch {
+					batch = append(batch, KV[int, int]{r.IntN(tt.size + tt.batch), r.IntN(20)})
+				}
+				var wantAdded, wantUpdated int
+				for _, el := range batch {
+					if _, ok := want.Get(el.Key); ok {
+						wantUpdated++
+					} else {
+						wantAdded++
+					}
+					want.Insert(el.Key, el.Val)
+				}
+
+				added, updated := sm.InsertMany(func(yield func(int, int) bool) {
+					for _, el := range batch {
+						if !yield(el.Key, el.Val) {
+							return
+						}
+					}
+				})
+				if added != wantAdded || updated != wantUpdated {
+					t.Errorf("InsertMany() = %d, %d, want %d, %d", added, updated, wantAdded, wantUpdated)
+				}
+				if sm.Len() != want.Len() {
+					t.Errorf("Len() = %d, want %d", sm.Len(), want.Len())
+				}
+				if s != Heap {
+					if !slices.Equal(sm.CollectAll(), want.CollectAll()) {
+						t.Errorf("InsertMany() = %v, want %v", sm.CollectAll(), want.CollectAll())
+					}
+				} else {
+					checkSorted(t, sm.CollectAll(), want.Collect(), byVal)
+				}
+				switch b := sm.b.(type) {
+				case *tree[KV[int, int]]:
+					checkTree(t, b, b.root)
+				case *btree[KV[int, int]]:
+					checkBTree(t, b, b.root, true)
+				}
+
+				// the map keeps working after the batch
+				for k := range want.Keys() {
+					sm.Delete(k)
+				}
+				if sm.Len() != 0 || len(sm.CollectAll()) != 0 {
+					t.Errorf("map is not empty after deleting all keys: %v", sm.CollectAll())
+				}
+			})
+		}
+	}
+}
+
+func TestSortedMap_DeleteMany(t *testing.T) {
+	byKey := func(i, j KV[int, string]) bool {
+		return i.Key < j.Key
+	}
+	tests := []struct {
+		name        string
+		keys        []int
+		wantDeleted int
+	}{
+		{"none", nil, 0},
+		{"missing", []int{100, 200}, 0},
+		{"few", []int{3, 100}, 1},
+		{"many", []int{0, 2, 4, 6, 8, 2, 100}, 5},
+		{"all", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 10},
+	}
+	for _, tt := range tests {
+		for _, s := range storages {
+			t.Run(tt.name+"/"+s.String(), func(t *testing.T) {
+				m := make(map[int]string)
+				for i := range 10 {
+					m[i] = fmt.Sprint(i)
+				}
+				sm := NewFromMap(m, byKey, WithStorage(s))
+
+				deleted := sm.DeleteMany(slices.Values(tt.keys))
+				if deleted != tt.wantDeleted {
+					t.Errorf("DeleteMany() = %d, want %d", deleted, tt.wantDeleted)
+				}
+				for _, k := range tt.keys {
+					delete(m, k)
+				}
+				checkSorted(t, sm.CollectAll(), m, byKey)
+				if sm.Len() != len(m) {
+					t.Errorf("Len() = %d, want %d", sm.Len(), len(m))
+				}
+
+				sm.Insert(4, "four")
+				m[4] = "four"
+				checkSorted(t, sm.CollectAll(), m, byKey)
+			})
+		}
+	}
+}
+
+func TestSortedMap_InsertMany_snapshot(t *testing.T) {
+	sm := NewFromMap(map[int]int{1: 1, 2: 2}, func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+	snap := sm.Snapshot()
+
+	sm.InsertMany(maps.All(map[int]int{2: 20, 3: 30}))
+	sm.DeleteMany(slices.Values([]int{1}))
+
+	if want := []KV[int, int]{{1, 1}, {2, 2}}; !slices.Equal(snap.CollectAll(), want) {
+		t.Errorf("Snapshot.CollectAll() = %v, want %v", snap.CollectAll(), want)
+	}
+	if want := []KV[int, int]{{2, 20}, {3, 30}}; !slices.Equal(sm.CollectAll(), want) {
+		t.Errorf("CollectAll() = %v, want %v", sm.CollectAll(), want)
+	}
+}
+
+func ExampleSortedMap_InsertMany() {
+	sm := NewFromMap(map[string]int{"a": 1, "b": 2}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	added, updated := sm.InsertMany(maps.All(map[string]int{"b": 20, "c": 30}))
+	fmt.Println(added, updated, sm.CollectAll())
+	// Output: 1 1 [{a 1} {b 20} {c 30}]
+}
+
+func BenchmarkSortedMap_InsertMany(b *testing.B) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	base := make([]KV[int, int], 10_000)
+	for i := range base {
+		base[i] = KV[int, int]{i * 2, i}
+	}
+	batch := make(map[int]int, 10_000)
+	for i := range 10_000 {
+		batch[i*3] = i
+	}
+
+	for _, s := range storages {
+		b.Run(s.String()+"/InsertMany", func(b *testing.B) {
+			for range b.N {
+				b.StopTimer()
+				sm, _ := NewFromSlice[map[int]int](base, less, WithStorage(s))
+				b.StartTimer()
+				sm.InsertMany(maps.All(batch))
+			}
+		})
+		b.Run(s.String()+"/Insert", func(b *testing.B) {
+			for range b.N {
+				b.StopTimer()
+				sm, _ := NewFromSlice[map[int]int](base, less, WithStorage(s))
+				b.StartTimer()
+				for k, v := range batch {
+					sm.Insert(k, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sortedmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// ErrBadEncoding is returned on decoding data which is not a binary encoding of a SortedMap.
+var ErrBadEncoding = errors.New("sortedmap: invalid binary encoding")
+
+// binaryMagic starts every binary encoding, binaryVersion follows it.
+const (
+	binaryMagic   = "SMAP"
+	binaryVersion = 1
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a versioned header followed
+// by the pairs in the order of the map encoded with encoding/gob, so K and V have to be supported by gob.
+// The complexity is O(n)
+func (sm *SortedMap[Map, K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(binaryMagic)
+	buf.WriteByte(binaryVersion)
+	if err := gob.NewEncoder(&buf).Encode(sm.CollectAll()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The pairs are inserted into the map like
+// UnmarshalJSON does. If the map is empty and the pairs are in its order, which is the case when the map was
+// encoded with the same comparison function, it's built in O(n) instead of inserting the pairs one by one.
+// The map has to be created with New first, since a zero value has no comparison function.
+func (sm *SortedMap[Map, K, V]) UnmarshalBinary(data []byte) error {
+	if sm.less == nil {
+		return ErrNoLess
+	}
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) || len(data) == len(binaryMagic) {
+		return ErrBadEncoding
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrBadEncoding, v)
+	}
+
+	var pairs []KV[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data[len(binaryMagic)+1:])).Decode(&pairs); err != nil {
+		return fmt.Errorf("%w: %w", ErrBadEncoding, err)
+	}
+	sm.load(pairs)
+
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder, the encoding is the same as of MarshalBinary.
+func (sm *SortedMap[Map, K, V]) GobEncode() ([]byte, error) {
+	return sm.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder, see UnmarshalBinary.
+func (sm *SortedMap[Map, K, V]) GobDecode(data []byte) error {
+	return sm.UnmarshalBinary(data)
+}
+
+// load inserts the pairs into the map. An empty map is built from sorted pairs in O(n), the pairs are sorted first
+// if they are not. If a key is repeated, the last pair wins like with Insert.
+func (sm *SortedMap[Map, K, V]) load(pairs []KV[K, V]) {
+	_ = sm.loadWith(pairs, false, KeepLast)
+}
@@ -0,0 +1,194 @@
+package sortedmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSortedMap_MarshalBinary(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool {
+		return i.Val/10 < j.Val/10
+	}
+	data := map[string]int{
+		"Alice":   30,
+		"Bob":     42,
+		"Charlie": 25,
+		"David":   35,
+		"Eve":     20,
+		"Frank":   41,
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(data, byVal, WithStorage(s))
+			b, err := sm.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+
+			got := New[map[string]int](byVal, WithStorage(s))
+			if err := got.UnmarshalBinary(b); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			checkSorted(t, got.CollectAll(), data, byVal)
+			// equal pairs keep their order too, except the heap which orders them its own way
+			if s != Heap && !slices.Equal(got.CollectAll(), sm.CollectAll()) {
+				t.Errorf("UnmarshalBinary() = %v, want %v", got.CollectAll(), sm.CollectAll())
+			}
+			if !maps.Equal(got.Collect(), data) || got.Len() != len(data) {
+				t.Errorf("Collect() = %v, want %v", got.Collect(), data)
+			}
+			got.Insert("Grace", 10)
+			got.Delete("Bob")
+			if k, _, _ := got.Min(); k != "Grace" {
+				t.Errorf("Min() = %v, want Grace", k)
+			}
+		})
+	}
+}
+
+func TestSortedMap_UnmarshalBinary(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool { return i.Val < j.Val }
+	encode := func(pairs ...KV[string, int]) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(binaryMagic)
+		buf.WriteByte(binaryVersion)
+		if err := gob.NewEncoder(&buf).Encode(pairs); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		init    map[string]int
+		data    []byte
+		want    []KV[string, int]
+		wantErr error
+	}{
+		{
+			name: "sorted pairs",
+			data: encode(KV[string, int]{"Alice", 3}, KV[string, int]{"Bob", 2}),
+			want: []KV[string, int]{{"Bob", 2}, {"Alice", 3}},
+		},
+		{
+			name: "unsorted pairs",
+			data: encode(KV[string, int]{"Alice", 3}, KV[string, int]{"Bob", 2}, KV[string, int]{"Eve", 1}),
+			want: []KV[string, int]{{"Eve", 1}, {"Bob", 2}, {"Alice", 3}},
+		},
+		{
+			name: "repeated keys",
+			data: encode(KV[string, int]{"Alice", 3}, KV[string, int]{"Alice", 1}),
+			want: []KV[string, int]{{"Alice", 1}},
+		},
+		{
+			name: "not empty map",
+			init: map[string]int{"Alice": 10, "Eve": 0},
+			data: encode(KV[string, int]{"Alice", 3}, KV[string, int]{"Bob", 2}),
+			want: []KV[string, int]{{"Eve", 0}, {"Bob", 2}, {"Alice", 3}},
+		},
+		{
+			name:    "no header",
+			data:    []byte("JSON"),
+			wantErr: ErrBadEncoding,
+		},
+		{
+			name:    "no version",
+			data:    []byte(binaryMagic),
+			wantErr: ErrBadEncoding,
+		},
+		{
+			name:    "unknown version",
+			data:    append([]byte(binaryMagic), 42),
+			wantErr: ErrBadEncoding,
+		},
+		{
+			name:    "truncated",
+			data:    encode(KV[string, int]{"Alice", 3})[:10],
+			wantErr: ErrBadEncoding,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewFromMap(tt.init, byVal)
+			err := sm.UnmarshalBinary(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UnmarshalBinary() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(sm.CollectAll(), tt.want) {
+				t.Errorf("UnmarshalBinary() = %v, want %v", sm.CollectAll(), tt.want)
+			}
+		})
+	}
+
+	var zero SortedMap[map[string]int, string, int]
+	if err := zero.UnmarshalBinary(encode()); !errors.Is(err, ErrNoLess) {
+		t.Errorf("UnmarshalBinary() into a zero value error = %v, want %v", err, ErrNoLess)
+	}
+}
+
+func TestSortedMap_GobEncode(t *testing.T) {
+	type checkpoint struct {
+		Name  string
+		Users *SortedMap[map[string]int, string, int]
+	}
+	less := func(i, j KV[string, int]) bool { return i.Key < j.Key }
+
+	var buf bytes.Buffer
+	in := checkpoint{"users", NewFromMap(map[string]int{"Bob": 42, "Alice": 30}, less)}
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	out := checkpoint{Users: New[map[string]int](less)}
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out.Name != in.Name || !slices.Equal(out.Users.CollectAll(), in.Users.CollectAll()) {
+		t.Errorf("Decode() = %v %v, want %v %v", out.Name, out.Users.CollectAll(), in.Name, in.Users.CollectAll())
+	}
+}
+
+func ExampleSortedMap_MarshalBinary() {
+	less := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, less)
+
+	data, _ := sm.MarshalBinary()
+	restored := New[map[string]int](less)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	fmt.Println(restored.CollectKeys())
+	// Output: [Charlie Alice Bob]
+}
+
+func BenchmarkSortedMap_UnmarshalBinary(b *testing.B) {
+	const n = 100_000
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	sm := New[map[int]int](less)
+	for i := 0; i < n; i++ {
+		sm.Insert(i, i)
+	}
+	data, err := sm.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := New[map[int]int](less).UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package sortedmap
+
+import (
+	"iter"
+	"slices"
+)
+
+// btreeDegree is the minimum degree of the B-tree: every node but the root holds
+// from btreeDegree-1 to 2*btreeDegree-1 items.
+const btreeDegree = 16
+
+// btree is a B-tree. Equal items are kept in insertion order.
+type btree[T any] struct {
+	root *bnode[T]
+	len  int
+	less func(a, b T) bool
+	same func(a, b T) bool
+}
+
+// bnode is a B-tree node. Leaves have no children, inner nodes have len(items)+1 children.
+type bnode[T any] struct {
+	items    []T
+	children []*bnode[T]
+}
+
+func newBTree[T any](less, same func(a, b T) bool) *btree[T] {
+	return &btree[T]{
+		root: &bnode[T]{},
+		less: less,
+		same: same,
+	}
+}
+
+// Len returns the number of items in the tree.
+func (b *btree[T]) Len() int {
+	return b.len
+}
+
+// Insert adds the item after all the items which are not greater than it.
+// The complexity is O(log n)
+func (b *btree[T]) Insert(item T) {
+	if len(b.root.items) == 2*btreeDegree-1 {
+		b.root = &bnode[T]{children: []*bnode[T]{b.root}}
+		b.root.split(0)
+	}
+
+	x := b.root
+	for {
+		i := b.upperBound(x, item)
+		if x.leaf() {
+			x.items = slices.Insert(x.items, i, item)
+			break
+		}
+		if len(x.children[i].items) == 2*btreeDegree-1 {
+			x.split(i)
+			if !b.less(item, x.items[i]) {
+				i++
+			}
+		}
+		x = x.children[i]
+	}
+	b.len++
+}
+
+// load replaces the content of the tree with the items, which have to be in ascending order.
+// The complexity is O(n)
+func (b *btree[T]) load(items []T) {
+	// the lowest tree which fits the items, a subtree of height h fits up to (2*btreeDegree)^h - 1 items
+	height, span := 1, 1
+	for span*2*btreeDegree < len(items)+1 {
+		height++
+		span *= 2 * btreeDegree
+	}
+	b.root = b.build(items, height, span, 2)
+	b.len = len(items)
+}
+
+// build returns a subtree of the given height with the sorted items. Every child fits up to span-1 items, and
+// the node has at least minChildren children. The items are shared evenly between the children, which makes
+// every child hold at least the minimum number of items for its height.
+func (b *btree[T]) build(items []T, height, span, minChildren int) *bnode[T] {
+	if height == 1 {
+		return &bnode[T]{items: slices.Clone(items)}
+	}
+
+	// a subtree with k items and the separator after it take k+1 items of the parent
+	m := len(items) + 1
+	c := max(minChildren, (m+span-1)/span)
+	x := &bnode[T]{items: make([]T, 0, c-1), children: make([]*bnode[T], 0, c)}
+	for i := range c {
+		share := m / c
+		if i < m%c {
+			share++
+		}
+		x.children = append(x.children, b.build(items[:share-1], height-1, span/(2*btreeDegree), btreeDegree))
+		if i < c-1 {
+			x.items = append(x.items, items[share-1])
+			items = items[share:]
+		}
+	}
+
+	return x
+}
+
+// Delete removes the item which is the same as the given one and reports whether it was found.
+// The complexity is O(log n) plus the number of items equal to the given one.
+func (b *btree[T]) Delete(item T) bool {
+	_, found := b.remove(b.root, item, bremoveItem)
+	// the root might have been merged into its only child on the way down, even if the item wasn't found
+	if len(b.root.items) == 0 && !b.root.leaf() {
+		b.root = b.root.children[0]
+	}
+	if found {
+		b.len--
+	}
+
+	return found
+}
+
+// All returns a sequence of the items in ascending order.
+// The complexity is O(n) for the whole traversal.
+func (b *btree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		b.ascend(b.root, nil, yield)
+	}
+}
+
+// Backward returns a sequence of the items in descending order.
+// The complexity is O(n) for the whole traversal.
+func (b *btree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		b.descend(b.root, nil, yield)
+	}
+}
+
+// Seek returns a sequence of the items in ascending order, starting from the first one which is not less than probe.
+// The complexity is O(log n) to find the first item.
+func (b *btree[T]) Seek(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		b.ascend(b.root, &probe, yield)
+	}
+}
+
+// SeekBackward returns a sequence of the items in descending order, starting from the last one which is not
+// greater than probe.
+// The complexity is O(log n) to find the first item.
+func (b *btree[T]) SeekBackward(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		b.descend(b.root, &probe, yield)
+	}
+}
+
+// AllFrom returns a sequence of the items in ascending order, starting from the i-th one.
+// The complexity is O(n)
+func (b *btree[T]) AllFrom(i int) iter.Seq[T] {
+	return skipItems(b.All(), i)
+}
+
+// Rank returns the position of the item which is the same as the given one, or -1 if there is no such item.
+// The complexity is O(n)
+func (b *btree[T]) Rank(item T) int {
+	return rankOf(b.All(), item, b.same)
+}
+
+// At returns the i-th item and false if there is no such item.
+// The complexity is O(n)
+func (b *btree[T]) At(i int) (T, bool) {
+	return itemAt(b.All(), i)
+}
+
+// Min returns the first item and false if the tree is empty.
+// The complexity is O(log n)
+func (b *btree[T]) Min() (T, bool) {
+	x := b.root
+	for !x.leaf() {
+		x = x.children[0]
+	}
+	if len(x.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return x.items[0], true
+}
+
+// Max returns the last item and false if the tree is empty.
+// The complexity is O(log n)
+func (b *btree[T]) Max() (T, bool) {
+	x := b.root
+	for !x.leaf() {
+		x = x.children[len(x.children)-1]
+	}
+	if len(x.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return x.items[len(x.items)-1], true
+}
+
+// ascend walks the subtree in order, skipping the items which are less than probe if it's not nil.
+func (b *btree[T]) ascend(x *bnode[T], probe *T, yield func(T) bool) bool {
+	i := 0
+	if probe != nil {
+		i = b.lowerBound(x, *probe)
+	}
+	for ; i <= len(x.items); i++ {
+		if !x.leaf() && !b.ascend(x.children[i], probe, yield) {
+			return false
+		}
+		// only the first visited child may contain items less than probe
+		probe = nil
+		if i < len(x.items) && !yield(x.items[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// descend walks the subtree in reverse order, skipping the items which are greater than probe if it's not nil.
+func (b *btree[T]) descend(x *bnode[T], probe *T, yield func(T) bool) bool {
+	i := len(x.items)
+	if probe != nil {
+		i = b.upperBound(x, *probe)
+	}
+	for ; i >= 0; i-- {
+		if !x.leaf() && !b.descend(x.children[i], probe, yield) {
+			return false
+		}
+		// only the first visited child may contain items greater than probe
+		probe = nil
+		if i > 0 && !yield(x.items[i-1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bremoveKind tells btree.remove which item to delete.
+type bremoveKind int
+
+const (
+	bremoveItem bremoveKind = iota
+	bremoveMin
+	bremoveMax
+)
+
+// remove deletes an item from the subtree rooted at x and returns it. Before descending into a child
+// it makes sure the child has at least btreeDegree items, so the deletion never has to go back up.
+func (b *btree[T]) remove(x *bnode[T], item T, kind bremoveKind) (T, bool) {
+	for {
+		var (
+			i     int
+			found bool
+		)
+		switch kind {
+		case bremoveMin:
+			i, found = 0, x.leaf() && len(x.items) > 0
+		case bremoveMax:
+			i, found = len(x.items), x.leaf() && len(x.items) > 0
+			if found {
+				i--
+			}
+		default:
+			i, found = b.locate(x, item)
+		}
+
+		if x.leaf() {
+			if !found {
+				var zero T
+				return zero, false
+			}
+			out := x.items[i]
+			x.items = slices.Delete(x.items, i, i+1)
+
+			return out, true
+		}
+
+		if found {
+			out := x.items[i]
+			switch {
+			case len(x.children[i].items) >= btreeDegree:
+				x.items[i], _ = b.remove(x.children[i], item, bremoveMax)
+			case len(x.children[i+1].items) >= btreeDegree:
+				x.items[i], _ = b.remove(x.children[i+1], item, bremoveMin)
+			default:
+				// both neighbors are minimal, so the item goes down into their union
+				x.merge(i)
+				continue
+			}
+
+			return out, true
+		}
+
+		if i < 0 {
+			var zero T
+			return zero, false
+		}
+		if len(x.children[i].items) < btreeDegree {
+			x.grow(i)
+			// items might have moved between x and its children, look for the item again
+			continue
+		}
+		x = x.children[i]
+	}
+}
+
+// locate finds the item in x. It returns the index of the item and true if x holds it,
+// the index of the child which holds it and false if it's deeper, or -1 and false if the subtree doesn't have it.
+func (b *btree[T]) locate(x *bnode[T], item T) (int, bool) {
+	lo, hi := b.lowerBound(x, item), b.upperBound(x, item)
+	for i := lo; i < hi; i++ {
+		if b.same(item, x.items[i]) {
+			return i, true
+		}
+	}
+	if x.leaf() {
+		return -1, false
+	}
+	if lo == hi {
+		// no equal items here, so only one child can hold the item
+		return lo, false
+	}
+	for i := lo; i <= hi; i++ {
+		if b.contains(x.children[i], item) {
+			return i, false
+		}
+	}
+
+	return -1, false
+}
+
+func (b *btree[T]) contains(x *bnode[T], item T) bool {
+	i, found := b.locate(x, item)
+	if found {
+		return true
+	}
+
+	return i >= 0 && b.contains(x.children[i], item)
+}
+
+// lowerBound returns the index of the first item in x which is not less than the given one.
+func (b *btree[T]) lowerBound(x *bnode[T], item T) int {
+	i, _ := slices.BinarySearchFunc(x.items, item, func(e, item T) int {
+		if b.less(e, item) {
+			return -1
+		}
+
+		return 1
+	})
+
+	return i
+}
+
+// upperBound returns the index of the first item in x which is greater than the given one.
+func (b *btree[T]) upperBound(x *bnode[T], item T) int {
+	i, _ := slices.BinarySearchFunc(x.items, item, func(e, item T) int {
+		if b.less(item, e) {
+			return 1
+		}
+
+		return -1
+	})
+
+	return i
+}
+
+func (x *bnode[T]) leaf() bool {
+	return len(x.children) == 0
+}
+
+// split moves the median item of the full child i up into x, and the items after it into a new child.
+func (x *bnode[T]) split(i int) {
+	y := x.children[i]
+	z := &bnode[T]{items: slices.Clone(y.items[btreeDegree:])}
+	if !y.leaf() {
+		z.children = slices.Clone(y.children[btreeDegree:])
+		clear(y.children[btreeDegree:])
+		y.children = y.children[:btreeDegree]
+	}
+	median := y.items[btreeDegree-1]
+	clear(y.items[btreeDegree-1:])
+	y.items = y.items[:btreeDegree-1]
+
+	x.items = slices.Insert(x.items, i, median)
+	x.children = slices.Insert(x.children, i+1, z)
+}
+
+// grow makes child i hold at least btreeDegree items by borrowing an item from a sibling,
+// or by merging the child with a sibling if both are minimal.
+func (x *bnode[T]) grow(i int) {
+	child := x.children[i]
+	switch {
+	case i > 0 && len(x.children[i-1].items) >= btreeDegree:
+		left := x.children[i-1]
+		child.items = slices.Insert(child.items, 0, x.items[i-1])
+		x.items[i-1] = left.items[len(left.items)-1]
+		left.items = slices.Delete(left.items, len(left.items)-1, len(left.items))
+		if !left.leaf() {
+			child.children = slices.Insert(child.children, 0, left.children[len(left.children)-1])
+			left.children = slices.Delete(left.children, len(left.children)-1, len(left.children))
+		}
+	case i < len(x.items) && len(x.children[i+1].items) >= btreeDegree:
+		right := x.children[i+1]
+		child.items = append(child.items, x.items[i])
+		x.items[i] = right.items[0]
+		right.items = slices.Delete(right.items, 0, 1)
+		if !right.leaf() {
+			child.children = append(child.children, right.children[0])
+			right.children = slices.Delete(right.children, 0, 1)
+		}
+	case i < len(x.items):
+		x.merge(i)
+	default:
+		x.merge(i - 1)
+	}
+}
+
+// merge joins child i, item i and child i+1 into child i.
+func (x *bnode[T]) merge(i int) {
+	left, right := x.children[i], x.children[i+1]
+	left.items = append(append(left.items, x.items[i]), right.items...)
+	left.children = append(left.children, right.children...)
+	x.items = slices.Delete(x.items, i, i+1)
+	x.children = slices.Delete(x.children, i+1, i+2)
+}
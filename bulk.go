@@ -0,0 +1,149 @@
+package sortedmap
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// ErrDuplicateKey is returned by NewFromSlice and NewFromSeq with the RejectDuplicates policy.
+var ErrDuplicateKey = errors.New("sortedmap: duplicate key")
+
+// DuplicatePolicy tells NewFromSlice and NewFromSeq what to do with a key which is repeated in the input.
+type DuplicatePolicy int
+
+const (
+	// KeepLast keeps the last pair of a key like a series of Insert calls does
+	KeepLast DuplicatePolicy = iota
+	// KeepFirst keeps the first pair of a key
+	KeepFirst
+	// RejectDuplicates fails with ErrDuplicateKey
+	RejectDuplicates
+)
+
+// NewFromSlice creates a new SortedMap with `less` as the comparison function and populates it with the pairs.
+// The map is built at once instead of inserting the pairs one by one. The pairs are sorted first if they are not
+// in order, with WithPresorted they are trusted to be. The Heap storage is built from pairs in any order.
+// A repeated key is handled by WithDuplicates, the last pair wins by default. The slice is not changed.
+// The complexity is O(n) for sorted pairs and for Heap, O(n log n) otherwise
+func NewFromSlice[Map ~map[K]V, K comparable, V any](
+	pairs []KV[K, V],
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) (*SortedMap[Map, K, V], error) {
+	sm := New[Map, K, V](less, opts...)
+	o := newOptions(opts)
+	if err := sm.loadWith(slices.Clone(pairs), o.presorted, o.duplicates); err != nil {
+		return nil, err
+	}
+
+	return sm, nil
+}
+
+// NewFromSeq creates a new SortedMap with `less` as the comparison function and populates it with the pairs
+// of the sequence, see NewFromSlice.
+// The complexity is O(n) for sorted pairs and for Heap, O(n log n) otherwise
+func NewFromSeq[Map ~map[K]V, K comparable, V any](
+	seq iter.Seq2[K, V],
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) (*SortedMap[Map, K, V], error) {
+	sm := New[Map, K, V](less, opts...)
+	o := newOptions(opts)
+	var pairs []KV[K, V]
+	for k, v := range seq {
+		pairs = append(pairs, KV[K, V]{k, v})
+	}
+	if err := sm.loadWith(pairs, o.presorted, o.duplicates); err != nil {
+		return nil, err
+	}
+
+	return sm, nil
+}
+
+// loadWith inserts the pairs into the map, the slice is reused. An empty map is built from sorted pairs in O(n),
+// the pairs are sorted first unless they are presorted or the storage is a heap. The map stays empty if the pairs
+// are rejected. A non-empty map gets the pairs inserted one by one, the last pair of a key wins then.
+func (sm *SortedMap[Map, K, V]) loadWith(pairs []KV[K, V], presorted bool, dup DuplicatePolicy) error {
+	if sm.Len() > 0 {
+		for _, el := range pairs {
+			sm.Insert(el.Key, el.Val)
+		}
+		return nil
+	}
+
+	pairs, err := sm.dedupe(pairs, dup)
+	if err != nil {
+		return err
+	}
+	// the heap doesn't need the pairs sorted to be built in O(n)
+	if h, ok := sm.b.(heapifier[KV[K, V]]); ok && !presorted {
+		h.heapify(pairs)
+		return nil
+	}
+	if !presorted && !slices.IsSortedFunc(pairs, sm.cmp) {
+		slices.SortStableFunc(pairs, sm.cmp)
+	}
+	loadSorted(sm.b, pairs)
+
+	return nil
+}
+
+// dedupe fills the empty index of the map with the pairs and returns the pairs without repeated keys,
+// in the same order. The slice is reused.
+func (sm *SortedMap[Map, K, V]) dedupe(pairs []KV[K, V], dup DuplicatePolicy) ([]KV[K, V], error) {
+	for _, el := range pairs {
+		sm.m[el.Key] = el.Val
+	}
+	if len(sm.m) == len(pairs) {
+		return pairs, nil
+	}
+
+	clear(sm.m)
+	switch dup {
+	case RejectDuplicates:
+		for _, el := range pairs {
+			if _, ok := sm.m[el.Key]; ok {
+				clear(sm.m)
+				return nil, fmt.Errorf("%w: %v", ErrDuplicateKey, el.Key)
+			}
+			sm.m[el.Key] = el.Val
+		}
+		return pairs, nil
+	case KeepFirst:
+		return sm.keepFirst(pairs), nil
+	}
+
+	// the last pair of a key is the first one from the end
+	slices.Reverse(pairs)
+	pairs = sm.keepFirst(pairs)
+	slices.Reverse(pairs)
+
+	return pairs, nil
+}
+
+func (sm *SortedMap[Map, K, V]) keepFirst(pairs []KV[K, V]) []KV[K, V] {
+	n := 0
+	for _, el := range pairs {
+		if _, ok := sm.m[el.Key]; !ok {
+			sm.m[el.Key] = el.Val
+			pairs[n] = el
+			n++
+		}
+	}
+
+	return pairs[:n]
+}
+
+// cmp is the comparison function of the map in the form of slices.SortFunc.
+func (sm *SortedMap[Map, K, V]) cmp(i, j KV[K, V]) int {
+	switch {
+	case sm.less(i, j):
+		return -1
+	case sm.less(j, i):
+		return 1
+	}
+
+	return 0
+}
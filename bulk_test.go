@@ -0,0 +1,232 @@
+package sortedmap
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestNewFromSlice(t *testing.T) {
+	byKey := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	tests := []struct {
+		name    string
+		pairs   []KV[string, int]
+		opts    []Option
+		want    []KV[string, int]
+		wantErr error
+	}{
+		{
+			name: "empty",
+			want: nil,
+		},
+		{
+			name:  "sorted",
+			pairs: []KV[string, int]{{"a", 1}, {"b", 2}, {"c", 3}},
+			want:  []KV[string, int]{{"a", 1}, {"b", 2}, {"c", 3}},
+		},
+		{
+			name:  "unsorted",
+			pairs: []KV[string, int]{{"c", 3}, {"a", 1}, {"b", 2}},
+			want:  []KV[string, int]{{"a", 1}, {"b", 2}, {"c", 3}},
+		},
+		{
+			name:  "presorted",
+			pairs: []KV[string, int]{{"a", 1}, {"b", 2}, {"c", 3}},
+			opts:  []Option{WithPresorted()},
+			want:  []KV[string, int]{{"a", 1}, {"b", 2}, {"c", 3}},
+		},
+		{
+			name:  "duplicates keep last",
+			pairs: []KV[string, int]{{"b", 1}, {"a", 2}, {"b", 3}, {"c", 4}, {"a", 5}},
+			want:  []KV[string, int]{{"a", 5}, {"b", 3}, {"c", 4}},
+		},
+		{
+			name:  "duplicates keep first",
+			pairs: []KV[string, int]{{"b", 1}, {"a", 2}, {"b", 3}, {"c", 4}, {"a", 5}},
+			opts:  []Option{WithDuplicates(KeepFirst)},
+			want:  []KV[string, int]{{"a", 2}, {"b", 1}, {"c", 4}},
+		},
+		{
+			name:  "presorted duplicates keep first",
+			pairs: []KV[string, int]{{"a", 1}, {"a", 2}, {"b", 3}},
+			opts:  []Option{WithPresorted(), WithDuplicates(KeepFirst)},
+			want:  []KV[string, int]{{"a", 1}, {"b", 3}},
+		},
+		{
+			name:    "duplicates rejected",
+			pairs:   []KV[string, int]{{"b", 1}, {"a", 2}, {"b", 3}},
+			opts:    []Option{WithDuplicates(RejectDuplicates)},
+			wantErr: ErrDuplicateKey,
+		},
+		{
+			name:  "no duplicates to reject",
+			pairs: []KV[string, int]{{"b", 1}, {"a", 2}},
+			opts:  []Option{WithDuplicates(RejectDuplicates)},
+			want:  []KV[string, int]{{"a", 2}, {"b", 1}},
+		},
+	}
+	for _, tt := range tests {
+		for _, s := range storages {
+			t.Run(tt.name+"/"+s.String(), func(t *testing.T) {
+				in := slices.Clone(tt.pairs)
+				sm, err := NewFromSlice[map[string]int](in, byKey, append(tt.opts, WithStorage(s))...)
+				if !slices.Equal(in, tt.pairs) {
+					t.Errorf("NewFromSlice() changed the input to %v", in)
+				}
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("NewFromSlice() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("NewFromSlice() error = %v", err)
+				}
+				if !slices.Equal(sm.CollectAll(), tt.want) {
+					t.Errorf("NewFromSlice() = %v, want %v", sm.CollectAll(), tt.want)
+				}
+				if sm.Len() != len(tt.want) {
+					t.Errorf("Len() = %d, want %d", sm.Len(), len(tt.want))
+				}
+				for _, el := range tt.want {
+					if v, ok := sm.Get(el.Key); !ok || v != el.Val {
+						t.Errorf("Get(%q) = %v, %v, want %v, true", el.Key, v, ok, el.Val)
+					}
+				}
+
+				// the map keeps working after the bulk load
+				sm.Insert("bb", 0)
+				sm.Delete("a")
+				want := maps.Collect(func(yield func(string, int) bool) {
+					for _, el := range tt.want {
+						if el.Key != "a" && !yield(el.Key, el.Val) {
+							return
+						}
+					}
+				})
+				want["bb"] = 0
+				checkSorted(t, sm.CollectAll(), want, byKey)
+			})
+		}
+	}
+}
+
+func TestNewFromSeq(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	m := map[string]int{"a": 3, "b": 1, "c": 2}
+
+	sm, err := NewFromSeq[map[string]int](maps.All(m), byVal)
+	if err != nil {
+		t.Fatalf("NewFromSeq() error = %v", err)
+	}
+	if want := []KV[string, int]{{"b", 1}, {"c", 2}, {"a", 3}}; !slices.Equal(sm.CollectAll(), want) {
+		t.Errorf("NewFromSeq() = %v, want %v", sm.CollectAll(), want)
+	}
+
+	// a sequence may repeat a key unlike a map
+	seq := func(yield func(string, int) bool) {
+		_ = yield("a", 1) && yield("b", 2) && yield("a", 3)
+	}
+	_, err = NewFromSeq[map[string]int](seq, byVal, WithDuplicates(RejectDuplicates))
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("NewFromSeq() error = %v, want %v", err, ErrDuplicateKey)
+	}
+	if err == nil || err.Error() != "sortedmap: duplicate key: a" {
+		t.Errorf("NewFromSeq() error = %v, want the key in it", err)
+	}
+}
+
+func TestNewFromSlice_comparisons(t *testing.T) {
+	const n = 10_000
+	reversed, sorted := make([]KV[int, int], n), make([]KV[int, int], n)
+	for i := range n {
+		reversed[i] = KV[int, int]{n - i, n - i}
+		sorted[i] = KV[int, int]{i, i}
+	}
+	tests := []struct {
+		name  string
+		pairs []KV[int, int]
+		s     Storage
+	}{
+		// the heap is built without sorting the pairs
+		{"Heap/unsorted", reversed, Heap},
+		{"Heap/sorted", sorted, Heap},
+		{"BTree/sorted", sorted, BTree},
+		{"Tree/sorted", sorted, Tree},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			less := func(i, j KV[int, int]) bool {
+				calls++
+				return i.Key < j.Key
+			}
+			sm, err := NewFromSlice[map[int]int](tt.pairs, less, WithStorage(tt.s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls > 2*n {
+				t.Errorf("NewFromSlice() made %d comparisons, want O(n)", calls)
+			}
+			if k, _, _ := sm.Min(); k != tt.pairs[0].Key && k != tt.pairs[n-1].Key {
+				t.Errorf("Min() = %d, want one of the ends", k)
+			}
+			checkSorted(t, sm.CollectAll(), maps.Collect(sm.All()), func(i, j KV[int, int]) bool {
+				return i.Key < j.Key
+			})
+		})
+	}
+}
+
+func ExampleNewFromSlice() {
+	events := []KV[string, int]{{"deploy", 3}, {"build", 1}, {"test", 2}, {"build", 4}}
+
+	sm, err := NewFromSlice[map[string]int](events, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}, WithDuplicates(KeepFirst))
+	if err != nil {
+		panic(err)
+	}
+	for k, v := range sm.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// build 1
+	// deploy 3
+	// test 2
+}
+
+func BenchmarkNewFromSlice(b *testing.B) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	pairs := make([]KV[int, int], 10_000)
+	for i := range pairs {
+		pairs[i] = KV[int, int]{i, i}
+	}
+
+	b.Run("sorted", func(b *testing.B) {
+		for range b.N {
+			_, _ = NewFromSlice[map[int]int](pairs, less)
+		}
+	})
+	b.Run("presorted", func(b *testing.B) {
+		for range b.N {
+			_, _ = NewFromSlice[map[int]int](pairs, less, WithPresorted())
+		}
+	})
+	b.Run("insert", func(b *testing.B) {
+		for range b.N {
+			sm := New[map[int]int](less)
+			for _, el := range pairs {
+				sm.Insert(el.Key, el.Val)
+			}
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package sortedmap
+
+import (
+	"iter"
+	"sync"
+)
+
+// ConcurrentSortedMap is a SortedMap which is safe for concurrent use by multiple goroutines.
+// Reads share a lock, writes are exclusive. Iterators walk a snapshot of the map taken when the iteration starts,
+// so they can be held for long and the map can be changed in the meantime.
+type ConcurrentSortedMap[Map ~map[K]V, K comparable, V any] struct {
+	mu sync.RWMutex
+	sm *SortedMap[Map, K, V]
+}
+
+// NewConcurrent creates a new ConcurrentSortedMap with `less` as the comparison function
+// The complexity is O(1)
+func NewConcurrent[Map ~map[K]V, K comparable, V any](
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) *ConcurrentSortedMap[Map, K, V] {
+	return &ConcurrentSortedMap[Map, K, V]{
+		sm: New[Map, K, V](less, opts...),
+	}
+}
+
+// NewConcurrentFromMap creates a new ConcurrentSortedMap with `less` as the comparison function and populates it
+// with the contents of `m`.
+// The complexity is O(n log n) where n = len(m).
+func NewConcurrentFromMap[Map ~map[K]V, K comparable, V any](
+	m Map,
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) *ConcurrentSortedMap[Map, K, V] {
+	return &ConcurrentSortedMap[Map, K, V]{
+		sm: NewFromMap(m, less, opts...),
+	}
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists in the map
+// The complexity is O(1)
+func (cm *ConcurrentSortedMap[Map, K, V]) Get(key K) (V, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.Get(key)
+}
+
+// Insert adds a key-value pair to the map. If the key already exists, the value is updated
+// The complexity is O(log n)
+func (cm *ConcurrentSortedMap[Map, K, V]) Insert(key K, val V) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.sm.Insert(key, val)
+}
+
+// Delete removes the key from the map and returns the value associated with the key and a boolean indicating
+// if the key existed in the map.
+// The complexity is O(log n)
+func (cm *ConcurrentSortedMap[Map, K, V]) Delete(key K) (val *V, existed bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.sm.Delete(key)
+}
+
+// Len returns length of underlying map
+func (cm *ConcurrentSortedMap[Map, K, V]) Len() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.Len()
+}
+
+// Min returns the first key-value pair in the order and a boolean indicating if the map is not empty
+// The complexity is O(log n)
+func (cm *ConcurrentSortedMap[Map, K, V]) Min() (K, V, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.Min()
+}
+
+// Max returns the last key-value pair in the order and a boolean indicating if the map is not empty
+// The complexity is O(log n)
+func (cm *ConcurrentSortedMap[Map, K, V]) Max() (K, V, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.Max()
+}
+
+// PopMin atomically removes the first key-value pair in the order from the map and returns it
+// with a boolean indicating if the map was not empty
+// The complexity is O(log n)
+func (cm *ConcurrentSortedMap[Map, K, V]) PopMin() (K, V, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.sm.PopMin()
+}
+
+// PopMax atomically removes the last key-value pair in the order from the map and returns it
+// with a boolean indicating if the map was not empty
+// The complexity is O(log n)
+func (cm *ConcurrentSortedMap[Map, K, V]) PopMax() (K, V, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.sm.PopMax()
+}
+
+// All returns a sequence of key-value pairs of a snapshot of the map
+// The complexity is O(n) to take the snapshot
+func (cm *ConcurrentSortedMap[Map, K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, el := range cm.CollectAll() {
+			if !yield(el.Key, el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns a sequence of keys of a snapshot of the map
+// The complexity is O(n) to take the snapshot
+func (cm *ConcurrentSortedMap[Map, K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, el := range cm.CollectAll() {
+			if !yield(el.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of values of a snapshot of the map
+// The complexity is O(n) to take the snapshot
+func (cm *ConcurrentSortedMap[Map, K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, el := range cm.CollectAll() {
+			if !yield(el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns a regular map with an *unordered* content off the map
+func (cm *ConcurrentSortedMap[Map, K, V]) Collect() Map {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.Collect()
+}
+
+// CollectAll returns a slice of key-value pairs
+func (cm *ConcurrentSortedMap[Map, K, V]) CollectAll() []KV[K, V] {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.CollectAll()
+}
+
+// CollectKeys returns a slice of the map’s keys
+func (cm *ConcurrentSortedMap[Map, K, V]) CollectKeys() []K {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.CollectKeys()
+}
+
+// CollectValues returns a slice of the map's values
+func (cm *ConcurrentSortedMap[Map, K, V]) CollectValues() []V {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.sm.CollectValues()
+}
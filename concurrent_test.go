@@ -0,0 +1,148 @@
+package sortedmap
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentSortedMap(t *testing.T) {
+	cm := NewConcurrentFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	cm.Insert("Eve", 84)
+	if v, ok := cm.Get("Eve"); !ok || v != 84 {
+		t.Errorf("Get(Eve) = %v, %v, want 84, true", v, ok)
+	}
+	if v, ok := cm.Delete("Bob"); !ok || *v != 42 {
+		t.Errorf("Delete(Bob) = %v, %v, want 42, true", ptrVal(v), ok)
+	}
+	if got := cm.Len(); got != 3 {
+		t.Errorf("Len() = %v, want 3", got)
+	}
+	if k, _, _ := cm.Min(); k != "Alice" {
+		t.Errorf("Min() = %v, want Alice", k)
+	}
+	if k, _, _ := cm.Max(); k != "Eve" {
+		t.Errorf("Max() = %v, want Eve", k)
+	}
+
+	// iteration doesn't hold the lock, so the map can be changed from the loop
+	var keys []string
+	for k := range cm.Keys() {
+		cm.Delete(k)
+		cm.Insert(k+"!", 0)
+		keys = append(keys, k)
+	}
+	if want := []string{"Alice", "Charlie", "Eve"}; fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("Keys() = %v, want %v", keys, want)
+	}
+	if want := []string{"Alice!", "Charlie!", "Eve!"}; fmt.Sprint(cm.CollectKeys()) != fmt.Sprint(want) {
+		t.Errorf("CollectKeys() = %v, want %v", cm.CollectKeys(), want)
+	}
+}
+
+func TestConcurrentSortedMap_race(t *testing.T) {
+	const (
+		writers = 8
+		readers = 8
+		ops     = 1000
+	)
+	cm := NewConcurrent[map[int]int](func(i, j KV[int, int]) bool {
+		return i.Val < j.Val
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < ops; i++ {
+				key := (w*ops + i) % 100
+				if i%5 == 0 {
+					cm.Delete(key)
+				} else {
+					cm.Insert(key, i)
+				}
+				if i%50 == 0 {
+					cm.PopMin()
+				}
+			}
+		}()
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < ops/10; i++ {
+				cm.Get(i % 100)
+				cm.Len()
+				// every snapshot has to be consistent
+				prev, n := -1, 0
+				for k, v := range cm.All() {
+					if v < prev {
+						t.Errorf("All() is not sorted: %d after %d", v, prev)
+					}
+					if got, ok := cm.Get(k); ok && got < 0 {
+						t.Errorf("Get(%d) = %d", k, got)
+					}
+					prev = v
+					n++
+				}
+				if n > 100 {
+					t.Errorf("All() yields %d pairs, want at most 100", n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkSorted(t, cm.CollectAll(), cm.Collect(), cm.sm.less)
+	if cm.Len() != len(cm.Collect()) {
+		t.Errorf("Len() = %d, want %d", cm.Len(), len(cm.Collect()))
+	}
+}
+
+func ExampleNewConcurrent() {
+	cm := NewConcurrent[map[string]int](func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	var wg sync.WaitGroup
+	for i, name := range []string{"Alice", "Bob", "Charlie"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cm.Insert(name, i)
+		}()
+	}
+	wg.Wait()
+
+	for k, v := range cm.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// Alice 0
+	// Bob 1
+	// Charlie 2
+}
+
+func BenchmarkConcurrentSortedMap(b *testing.B) {
+	cm := NewConcurrentFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i++; i%10 == 0 {
+				cm.Insert("Berik", i)
+			} else {
+				cm.Get("Roger")
+			}
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package sortedmap
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSkipRow can be returned by the parse function of ReadCSV to skip a row, e.g. a header.
+var ErrSkipRow = errors.New("sortedmap: skip csv row")
+
+// CSVError is returned by ReadCSV when a row can't be parsed.
+type CSVError struct {
+	// Row is the number of the row, starting from 1
+	Row int
+	// Line is the line of the row in the input, it differs from Row if there are multi-line fields
+	Line int
+	Err  error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("sortedmap: csv row %d (line %d): %v", e.Row, e.Line, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// WriteCSV writes the map to w as CSV, one row per key-value pair in the order of the map.
+// format turns a pair into the fields of its row.
+// The complexity is O(n)
+func (sm *SortedMap[Map, K, V]) WriteCSV(w io.Writer, format func(KV[K, V]) []string) error {
+	cw := csv.NewWriter(w)
+	for k, v := range sm.All() {
+		if err := cw.Write(format(KV[K, V]{k, v})); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// ReadCSV creates a new SortedMap with `less` as the comparison function from CSV rows read from r.
+// parse turns the fields of a row into a key-value pair, it can return ErrSkipRow to skip the row. The slice of
+// the fields is reused for the next row, so parse must not keep it.
+// Errors of parse are returned as *CSVError with the number of the row. If a key is repeated, the last row wins.
+// The complexity is O(n) if the rows are in the order of the map, O(n log n) otherwise
+func ReadCSV[Map ~map[K]V, K comparable, V any](
+	r io.Reader,
+	parse func([]string) (KV[K, V], error),
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) (*SortedMap[Map, K, V], error) {
+	sm := New[Map, K, V](less, opts...)
+	cr := csv.NewReader(r)
+	// the number of fields is up to parse
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	var pairs []KV[K, V]
+	for row := 1; ; row++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		kv, err := parse(rec)
+		if errors.Is(err, ErrSkipRow) {
+			continue
+		}
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, &CSVError{Row: row, Line: line, Err: err}
+		}
+		pairs = append(pairs, kv)
+	}
+	sm.load(pairs)
+
+	return sm, nil
+}
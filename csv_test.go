@@ -0,0 +1,174 @@
+package sortedmap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func formatCSV(kv KV[string, int]) []string {
+	return []string{kv.Key, strconv.Itoa(kv.Val)}
+}
+
+func parseCSV(rec []string) (KV[string, int], error) {
+	if len(rec) != 2 {
+		return KV[string, int]{}, fmt.Errorf("got %d fields, want 2", len(rec))
+	}
+	if rec[0] == "name" {
+		return KV[string, int]{}, ErrSkipRow
+	}
+	v, err := strconv.Atoi(rec[1])
+
+	return KV[string, int]{rec[0], v}, err
+}
+
+func TestSortedMap_WriteCSV(t *testing.T) {
+	sm := NewFromMap(map[string]int{
+		"Bob":             42,
+		"Alice":           30,
+		"Smith, John":     25,
+		"\"Quoted\"\nOne": 50,
+	}, func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	})
+
+	var buf bytes.Buffer
+	if err := sm.WriteCSV(&buf, formatCSV); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "\"Smith, John\",25\nAlice,30\nBob,42\n\"\"\"Quoted\"\"\nOne\",50\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+
+	back, err := ReadCSV[map[string]int](&buf, parseCSV, sm.less)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if !slices.Equal(back.CollectAll(), sm.CollectAll()) {
+		t.Errorf("ReadCSV() = %v, want %v", back.CollectAll(), sm.CollectAll())
+	}
+
+	if err := sm.WriteCSV(failingWriter{}, formatCSV); err == nil {
+		t.Errorf("WriteCSV() error = nil, want an error")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestReadCSV(t *testing.T) {
+	less := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	tests := []struct {
+		name     string
+		in       string
+		want     []KV[string, int]
+		wantRow  int
+		wantLine int
+		wantErr  bool
+	}{
+		{
+			name: "header is skipped",
+			in:   "name,age\nBob,42\nAlice,30\n",
+			want: []KV[string, int]{{"Alice", 30}, {"Bob", 42}},
+		},
+		{
+			name: "repeated key",
+			in:   "Bob,42\nBob,43\n",
+			want: []KV[string, int]{{"Bob", 43}},
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: []KV[string, int]{},
+		},
+		{
+			name:     "bad value",
+			in:       "name,age\nBob,42\nAlice,thirty\n",
+			wantRow:  3,
+			wantLine: 3,
+			wantErr:  true,
+		},
+		{
+			name:     "multi-line field before the bad row",
+			in:       "\"Bob\nSmith\",42\nAlice\n",
+			wantRow:  2,
+			wantLine: 3,
+			wantErr:  true,
+		},
+		{
+			name:    "malformed csv",
+			in:      "Bob,\"42\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm, err := ReadCSV[map[string]int](strings.NewReader(tt.in), parseCSV, less)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var csvErr *CSVError
+				if errors.As(err, &csvErr) != (tt.wantRow > 0) {
+					t.Fatalf("ReadCSV() error = %v, want a CSVError %v", err, tt.wantRow > 0)
+				}
+				if csvErr != nil && (csvErr.Row != tt.wantRow || csvErr.Line != tt.wantLine) {
+					t.Errorf("ReadCSV() error at row %d, line %d, want row %d, line %d",
+						csvErr.Row, csvErr.Line, tt.wantRow, tt.wantLine)
+				}
+				return
+			}
+			if got := sm.CollectAll(); !slices.Equal(got, tt.want) {
+				t.Errorf("ReadCSV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ExampleSortedMap_WriteCSV() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	})
+
+	_ = sm.WriteCSV(os.Stdout, func(kv KV[string, int]) []string {
+		return []string{kv.Key, strconv.Itoa(kv.Val)}
+	})
+	// Output:
+	// Charlie,25
+	// Alice,30
+	// Bob,42
+}
+
+func ExampleReadCSV() {
+	in := "name,age\nBob,42\nAlice,30\n"
+	sm, err := ReadCSV[map[string]int](strings.NewReader(in), func(rec []string) (KV[string, int], error) {
+		if rec[0] == "name" {
+			return KV[string, int]{}, ErrSkipRow
+		}
+		age, err := strconv.Atoi(rec[1])
+
+		return KV[string, int]{rec[0], age}, err
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(sm.CollectAll())
+	// Output: [{Alice 30} {Bob 42}]
+}
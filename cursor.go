@@ -0,0 +1,119 @@
+package sortedmap
+
+import "iter"
+
+// Cursor is a position in a SortedMap which can be moved in both directions.
+//
+// A cursor doesn't pin the map: it remembers the current key-value pair and every move looks up the neighbor
+// of that pair in the map, so the map can be changed between moves. If the current pair has been removed,
+// the cursor moves to the neighbor of the place where it used to be.
+type Cursor[Map ~map[K]V, K comparable, V any] struct {
+	sm    *SortedMap[Map, K, V]
+	cur   KV[K, V]
+	valid bool
+}
+
+// Cursor returns a new cursor over the map. It's not positioned until First, Last or Seek is called.
+func (sm *SortedMap[Map, K, V]) Cursor() *Cursor[Map, K, V] {
+	return &Cursor[Map, K, V]{sm: sm}
+}
+
+// First moves the cursor to the first key-value pair and reports whether there is one
+// The complexity is O(log n), O(1) for the Heap storage
+func (c *Cursor[Map, K, V]) First() bool {
+	c.cur, c.valid = c.sm.b.Min()
+
+	return c.valid
+}
+
+// Last moves the cursor to the last key-value pair and reports whether there is one
+// The complexity is O(log n), O(n log n) for the Heap storage
+func (c *Cursor[Map, K, V]) Last() bool {
+	// Max of the heap may be any of the greatest equal pairs, while Prev needs the one which is iterated last
+	c.valid = false
+	for el := range c.sm.b.Backward() {
+		c.cur, c.valid = el, true
+		break
+	}
+
+	return c.valid
+}
+
+// Seek moves the cursor to the first key-value pair which is not less than the probe and reports
+// whether there is one
+// The complexity is O(log n), O(n log n) for the Heap storage
+func (c *Cursor[Map, K, V]) Seek(probe KV[K, V]) bool {
+	c.valid = false
+	for el := range c.sm.b.Seek(probe) {
+		c.cur, c.valid = el, true
+		break
+	}
+
+	return c.valid
+}
+
+// Next moves the cursor to the next key-value pair and reports whether there is one
+// The complexity is O(log n) plus the number of pairs equal to the current one, O(n log n) for the Heap storage
+func (c *Cursor[Map, K, V]) Next() bool {
+	if !c.valid {
+		return false
+	}
+
+	c.valid = c.step(c.sm.b.Seek(c.cur), c.sm.less)
+
+	return c.valid
+}
+
+// Prev moves the cursor to the previous key-value pair and reports whether there is one
+// The complexity is O(log n) plus the number of pairs equal to the current one, O(n log n) for the Heap storage
+func (c *Cursor[Map, K, V]) Prev() bool {
+	if !c.valid {
+		return false
+	}
+
+	c.valid = c.step(c.sm.b.SeekBackward(c.cur), func(i, j KV[K, V]) bool {
+		return c.sm.less(j, i)
+	})
+
+	return c.valid
+}
+
+// step moves the cursor to the pair which follows the current one in seq. seq starts from the pairs which are
+// equal to the current one according to before, the current pair itself is one of them unless it has been removed.
+func (c *Cursor[Map, K, V]) step(seq iter.Seq[KV[K, V]], before func(i, j KV[K, V]) bool) bool {
+	passed := false
+	for el := range seq {
+		switch {
+		case passed || before(c.cur, el):
+			c.cur = el
+			return true
+		case el.Key == c.cur.Key:
+			passed = true
+		}
+	}
+
+	return false
+}
+
+// Valid reports whether the cursor is positioned on a key-value pair
+func (c *Cursor[Map, K, V]) Valid() bool {
+	return c.valid
+}
+
+// Key returns the key of the current pair, or the zero value if the cursor is not valid
+func (c *Cursor[Map, K, V]) Key() K {
+	if !c.valid {
+		return *new(K)
+	}
+
+	return c.cur.Key
+}
+
+// Val returns the value of the current pair, or the zero value if the cursor is not valid
+func (c *Cursor[Map, K, V]) Val() V {
+	if !c.valid {
+		return *new(V)
+	}
+
+	return c.cur.Val
+}
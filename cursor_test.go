@@ -0,0 +1,151 @@
+package sortedmap
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	// values are compared by tens, so the pairs with the same tens are equal
+	less := func(i, j KV[string, int]) bool {
+		return i.Val/10 < j.Val/10
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[string]int{
+				"a": 10, "b": 11, "c": 12,
+				"d": 20,
+				"e": 30, "f": 31,
+			}, less, WithStorage(s))
+			want := sm.CollectKeys()
+
+			c := sm.Cursor()
+			if c.Valid() || c.Next() || c.Prev() {
+				t.Fatalf("new cursor is valid")
+			}
+			var got []string
+			for ok := c.First(); ok; ok = c.Next() {
+				got = append(got, c.Key())
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("First() and Next() visit %v, want %v", got, want)
+			}
+			if c.Valid() || c.Key() != "" || c.Val() != 0 {
+				t.Errorf("cursor past the end is valid: %v %v %v", c.Valid(), c.Key(), c.Val())
+			}
+
+			got = got[:0]
+			for ok := c.Last(); ok; ok = c.Prev() {
+				got = append(got, c.Key())
+			}
+			for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+				want[i], want[j] = want[j], want[i]
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Last() and Prev() visit %v, want %v", got, want)
+			}
+
+			if !c.Seek(KV[string, int]{Val: 25}) || c.Key() != "d" || c.Val() != 20 {
+				t.Errorf("Seek(25) = %v, %v, want d, 20", c.Key(), c.Val())
+			}
+			if c.Seek(KV[string, int]{Val: 40}) {
+				t.Errorf("Seek(40) = %v, want false", c.Key())
+			}
+		})
+	}
+}
+
+func TestCursor_changes(t *testing.T) {
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5}, func(i, j KV[int, int]) bool {
+				return i.Key < j.Key
+			}, WithStorage(s))
+			c := sm.Cursor()
+			c.Seek(KV[int, int]{Key: 3})
+
+			// the current pair is removed, the cursor moves around its place
+			sm.Delete(3)
+			if !c.Next() || c.Key() != 4 {
+				t.Errorf("Next() after Delete() = %v, want 4", c.Key())
+			}
+			if !c.Prev() || c.Key() != 2 {
+				t.Errorf("Prev() = %v, want 2", c.Key())
+			}
+
+			sm.Insert(3, 3)
+			sm.Delete(1)
+			if !c.Next() || c.Key() != 3 {
+				t.Errorf("Next() after Insert() = %v, want 3", c.Key())
+			}
+			if !c.Prev() || c.Key() != 2 || c.Prev() {
+				t.Errorf("cursor doesn't stop at the first pair, at %v", c.Key())
+			}
+		})
+	}
+}
+
+func TestCursor_LastHeapTies(t *testing.T) {
+	sm := New[map[string]int](func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}, WithStorage(Heap))
+	// in this order of inserts Max of the heap is c, while Backward starts from d
+	for _, el := range []KV[string, int]{{"a", 1}, {"b", 2}, {"c", 2}, {"d", 2}} {
+		sm.Insert(el.Key, el.Val)
+	}
+
+	var got []string
+	c := sm.Cursor()
+	for ok := c.Last(); ok; ok = c.Prev() {
+		got = append(got, c.Key())
+	}
+	want := slices.Collect(func(yield func(string) bool) {
+		for el := range sm.b.Backward() {
+			if !yield(el.Key) {
+				return
+			}
+		}
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Last() and Prev() visit %v, want %v", got, want)
+	}
+}
+
+func ExampleSortedMap_Cursor() {
+	less := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	orders := NewFromMap(map[string]int{"Alice": 3, "Bob": 1, "Eve": 7}, less)
+	payments := NewFromMap(map[string]int{"Alice": 3, "Charlie": 2, "Eve": 5}, less)
+
+	// merge join: walk both maps in lockstep and match the keys
+	o, p := orders.Cursor(), payments.Cursor()
+	for okO, okP := o.First(), p.First(); okO && okP; {
+		switch {
+		case o.Key() < p.Key():
+			okO = o.Next()
+		case o.Key() > p.Key():
+			okP = p.Next()
+		default:
+			fmt.Println(o.Key(), o.Val(), p.Val())
+			okO, okP = o.Next(), p.Next()
+		}
+	}
+	// Output:
+	// Alice 3 3
+	// Eve 7 5
+}
+
+func BenchmarkCursor_Next(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	c := sm.Cursor()
+	for i := 0; i < b.N; i++ {
+		if !c.Next() {
+			c.First()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package sortedmap
+
+import (
+	"fmt"
+	"iter"
+)
+
+// ChangeKind is the kind of a change between two versions of a map.
+type ChangeKind int
+
+const (
+	// Added is a key which is only in the new version
+	Added ChangeKind = iota + 1
+	// Removed is a key which is only in the old version
+	Removed
+	// Modified is a key which is in both versions with different values
+	Modified
+)
+
+func (c ChangeKind) String() string {
+	switch c {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	}
+
+	return fmt.Sprintf("ChangeKind(%d)", int(c))
+}
+
+// Change is a difference between two versions of a map. Old is the zero value for Added and New is
+// the zero value for Removed.
+type Change[K comparable, V any] struct {
+	Kind ChangeKind
+	Key  K
+	Old  V
+	New  V
+}
+
+func (c Change[K, V]) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+%v: %v", c.Key, c.New)
+	case Removed:
+		return fmt.Sprintf("-%v: %v", c.Key, c.Old)
+	}
+
+	return fmt.Sprintf("~%v: %v -> %v", c.Key, c.Old, c.New)
+}
+
+// Diff returns a sequence of the changes which turn a into b, `eq` tells if two values are equal.
+// The changes are in the order of a: Removed at the position of the old pair, Added and Modified at the position
+// of the new one. The maps are expected to have the same comparison function. The pairs are read when an iteration
+// starts, so a can be patched while the sequence is iterated, e.g. Patch(a, Diff(a, b, eq)).
+// The complexity is O(n + m)
+func Diff[Map ~map[K]V, K comparable, V any](
+	a, b *SortedMap[Map, K, V],
+	eq func(x, y V) bool,
+) iter.Seq[Change[K, V]] {
+	return func(yield func(Change[K, V]) bool) {
+		merge(a, b, func(el KV[K, V], fromA bool) bool {
+			if fromA {
+				if b.has(el.Key) {
+					return true
+				}
+				return yield(Change[K, V]{Kind: Removed, Key: el.Key, Old: el.Val})
+			}
+
+			av, ok := a.Get(el.Key)
+			switch {
+			case !ok:
+				return yield(Change[K, V]{Kind: Added, Key: el.Key, New: el.Val})
+			case !eq(av, el.Val):
+				return yield(Change[K, V]{Kind: Modified, Key: el.Key, Old: av, New: el.Val})
+			}
+			return true
+		})
+	}
+}
+
+// Patch applies the changes to the map: the keys of Added and Modified are set to New, the keys of Removed
+// are deleted. Patch(a, Diff(a, b, eq)) makes a equal to b.
+// The complexity is O(k log n)
+func Patch[Map ~map[K]V, K comparable, V any](sm *SortedMap[Map, K, V], changes iter.Seq[Change[K, V]]) {
+	for c := range changes {
+		switch c.Kind {
+		case Added, Modified:
+			sm.Insert(c.Key, c.New)
+		case Removed:
+			sm.Delete(c.Key)
+		}
+	}
+}
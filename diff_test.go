@@ -0,0 +1,154 @@
+package sortedmap
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	byKey := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	eq := func(x, y int) bool {
+		return x == y
+	}
+	tests := []struct {
+		name string
+		a, b map[string]int
+		want []Change[string, int]
+	}{
+		{
+			name: "equal",
+			a:    map[string]int{"a": 1, "b": 2},
+			b:    map[string]int{"a": 1, "b": 2},
+			want: nil,
+		},
+		{
+			name: "all kinds",
+			a:    map[string]int{"a": 1, "b": 2, "c": 3, "e": 5},
+			b:    map[string]int{"b": 2, "c": 30, "d": 4, "e": 5},
+			want: []Change[string, int]{
+				{Kind: Removed, Key: "a", Old: 1},
+				{Kind: Modified, Key: "c", Old: 3, New: 30},
+				{Kind: Added, Key: "d", New: 4},
+			},
+		},
+		{
+			name: "from empty",
+			a:    map[string]int{},
+			b:    map[string]int{"a": 1, "b": 2},
+			want: []Change[string, int]{
+				{Kind: Added, Key: "a", New: 1},
+				{Kind: Added, Key: "b", New: 2},
+			},
+		},
+		{
+			name: "to empty",
+			a:    map[string]int{"a": 1, "b": 2},
+			b:    map[string]int{},
+			want: []Change[string, int]{
+				{Kind: Removed, Key: "a", Old: 1},
+				{Kind: Removed, Key: "b", Old: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := NewFromMap(tt.a, byKey), NewFromMap(tt.b, byKey)
+			got := slices.Collect(Diff(a, b, eq))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+
+			Patch(a, Diff(a, b, eq))
+			if !slices.Equal(a.CollectAll(), b.CollectAll()) {
+				t.Errorf("Patch() = %v, want %v", a.CollectAll(), b.CollectAll())
+			}
+		})
+	}
+}
+
+func TestDiff_byValue(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	eq := func(x, y int) bool {
+		return x == y
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			a := NewFromMap(map[string]int{"x": 1, "y": 5, "z": 9}, byVal, WithStorage(s))
+			b := NewFromMap(map[string]int{"x": 1, "y": 10, "w": 3}, byVal, WithStorage(s))
+
+			got := slices.Collect(Diff(a, b, eq))
+			want := []Change[string, int]{
+				{Kind: Added, Key: "w", New: 3},
+				{Kind: Removed, Key: "z", Old: 9},
+				{Kind: Modified, Key: "y", Old: 5, New: 10},
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Diff() = %v, want %v", got, want)
+			}
+
+			Patch(a, slices.Values(got))
+			if !slices.Equal(a.CollectAll(), b.CollectAll()) {
+				t.Errorf("Patch() = %v, want %v", a.CollectAll(), b.CollectAll())
+			}
+		})
+	}
+}
+
+func TestDiff_stop(t *testing.T) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	a := New[map[int]int](less)
+	b := NewFromMap(map[int]int{1: 1, 2: 2, 3: 3}, less)
+
+	var got []int
+	for c := range Diff(a, b, func(x, y int) bool { return x == y }) {
+		got = append(got, c.Key)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("Diff() = %v, want [1 2]", got)
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	tests := []struct {
+		c    Change[string, int]
+		want string
+	}{
+		{Change[string, int]{Kind: Added, Key: "a", New: 1}, "+a: 1"},
+		{Change[string, int]{Kind: Removed, Key: "a", Old: 1}, "-a: 1"},
+		{Change[string, int]{Kind: Modified, Key: "a", Old: 1, New: 2}, "~a: 1 -> 2"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+	if got := ChangeKind(0).String(); got != "ChangeKind(0)" {
+		t.Errorf("String() = %q, want %q", got, "ChangeKind(0)")
+	}
+}
+
+func ExampleDiff() {
+	less := func(i, j KV[string, string]) bool {
+		return i.Key < j.Key
+	}
+	before := NewFromMap(map[string]string{"host": "localhost", "port": "8080", "debug": "true"}, less)
+	after := NewFromMap(map[string]string{"host": "example.com", "port": "8080", "tls": "on"}, less)
+
+	for c := range Diff(before, after, func(x, y string) bool { return x == y }) {
+		fmt.Println(c.Kind, c)
+	}
+	// Output:
+	// Removed -debug: true
+	// Modified ~host: localhost -> example.com
+	// Added +tls: on
+}
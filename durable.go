@@ -0,0 +1,385 @@
+package sortedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// Durable is a SortedMap which survives process restarts. Every change is appended to a write-ahead log
+// in the directory before it's applied, and from time to time the whole map is written to a snapshot file
+// and the log starts over. OpenDurable loads the snapshot and replays the log on top of it.
+//
+// A record which was torn by a crash is detected by its checksum and dropped along with everything after it,
+// so the map is restored to the last change which made it to the disk completely.
+// Like SortedMap, Durable is not safe for concurrent use.
+type Durable[Map ~map[K]V, K comparable, V any] struct {
+	sm  *SortedMap[Map, K, V]
+	dir string
+	wal walFile
+	o   durableOptions
+	// records is the number of records in the log since the last checkpoint
+	records int
+	// size is the size of the log up to the end of the last complete record
+	size int64
+	// err is set when a failed record can't be cut off the log, every change is rejected with it then
+	err error
+}
+
+// walFile is the part of *os.File which the log uses.
+type walFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// SyncPolicy tells when Durable flushes the log to the disk.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes the log after every change. A change is never lost once Insert or Delete returns.
+	SyncAlways SyncPolicy = iota
+	// SyncNever leaves flushing to the operating system and Sync, Checkpoint and Close calls. The latest changes
+	// may be lost on a power failure, but not on a crash of the process.
+	SyncNever
+)
+
+// DurableOption configures a Durable map.
+type DurableOption func(*durableOptions)
+
+type durableOptions struct {
+	sync       SyncPolicy
+	checkpoint int
+	opts       []Option
+}
+
+// WithSyncPolicy sets when the log is flushed to the disk. The default is SyncAlways.
+func WithSyncPolicy(p SyncPolicy) DurableOption {
+	return func(o *durableOptions) {
+		o.sync = p
+	}
+}
+
+// WithCheckpointEvery makes Durable write a snapshot and start a new log after every n records.
+// Zero turns automatic checkpoints off. The default is 10 000 records.
+func WithCheckpointEvery(n int) DurableOption {
+	return func(o *durableOptions) {
+		o.checkpoint = n
+	}
+}
+
+// WithMapOptions sets the options of the underlying SortedMap, e.g. WithStorage.
+func WithMapOptions(opts ...Option) DurableOption {
+	return func(o *durableOptions) {
+		o.opts = opts
+	}
+}
+
+const (
+	durableSnapshotFile = "snapshot"
+	durableWALFile      = "wal"
+
+	walInsert byte = 1
+	walDelete byte = 2
+
+	// walHeaderSize is the size of the length and the checksum in front of every record
+	walHeaderSize = 8
+	// walMaxRecord protects from allocating memory for a garbage length
+	walMaxRecord = 1 << 30
+)
+
+var walCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCheckpoint is returned by Durable.Insert and Durable.Delete when the change is logged and applied,
+// but the automatic checkpoint after it fails. The log keeps growing then, and Checkpoint can be retried.
+var ErrCheckpoint = errors.New("sortedmap: checkpoint failed")
+
+// OpenDurable opens the durable map in the directory with `less` as the comparison function, creating
+// the directory if it doesn't exist. K and V have to be supported by encoding/gob.
+// The complexity is O(n + m log n) where m is the number of records in the log
+func OpenDurable[Map ~map[K]V, K comparable, V any](
+	dir string,
+	less func(i, j KV[K, V]) bool,
+	opts ...DurableOption,
+) (*Durable[Map, K, V], error) {
+	o := durableOptions{sync: SyncAlways, checkpoint: 10_000}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	d := &Durable[Map, K, V]{
+		sm:  New[Map, K, V](less, o.opts...),
+		dir: dir,
+		o:   o,
+	}
+	data, err := os.ReadFile(filepath.Join(dir, durableSnapshotFile))
+	switch {
+	case err == nil:
+		if err := d.sm.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("sortedmap: read snapshot: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(filepath.Join(dir, durableWALFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	d.wal = wal
+	if err := d.replay(); err != nil {
+		_ = d.wal.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists in the map
+// The complexity is O(1)
+func (d *Durable[Map, K, V]) Get(key K) (V, bool) {
+	return d.sm.Get(key)
+}
+
+// Insert logs and adds a key-value pair to the map. If the key already exists, the value is updated.
+// The map is not changed if the log can't be written, and the failed record is cut off the log.
+// An error wrapping ErrCheckpoint means the pair is logged and added, but the automatic checkpoint failed.
+// The complexity is O(log n)
+func (d *Durable[Map, K, V]) Insert(key K, val V) error {
+	if err := d.log(walInsert, KV[K, V]{key, val}); err != nil {
+		return err
+	}
+	d.sm.Insert(key, val)
+
+	return d.maybeCheckpoint()
+}
+
+// Delete logs and removes the key from the map and returns the value associated with the key and a boolean
+// indicating if the key existed in the map. The map is not changed if the log can't be written.
+// An error wrapping ErrCheckpoint means the key is logged and removed, but the automatic checkpoint failed.
+// The complexity is O(log n)
+func (d *Durable[Map, K, V]) Delete(key K) (val *V, existed bool, err error) {
+	if _, ok := d.sm.Get(key); !ok {
+		return (*V)(nil), false, nil
+	}
+	if err := d.log(walDelete, KV[K, V]{Key: key}); err != nil {
+		return (*V)(nil), false, err
+	}
+	val, existed = d.sm.Delete(key)
+
+	return val, existed, d.maybeCheckpoint()
+}
+
+// Len returns length of underlying map
+func (d *Durable[Map, K, V]) Len() int {
+	return d.sm.Len()
+}
+
+// All returns a sequence of key-value pairs
+func (d *Durable[Map, K, V]) All() iter.Seq2[K, V] {
+	return d.sm.All()
+}
+
+// Keys returns a sequence of keys
+func (d *Durable[Map, K, V]) Keys() iter.Seq[K] {
+	return d.sm.Keys()
+}
+
+// Values returns a sequence of values
+func (d *Durable[Map, K, V]) Values() iter.Seq[V] {
+	return d.sm.Values()
+}
+
+// Snapshot returns a read-only view of the map with all the read methods of SortedMap
+// The complexity is O(1), see SortedMap.Snapshot
+func (d *Durable[Map, K, V]) Snapshot() *Snapshot[Map, K, V] {
+	return d.sm.Snapshot()
+}
+
+// Sync flushes the log to the disk.
+func (d *Durable[Map, K, V]) Sync() error {
+	return d.wal.Sync()
+}
+
+// Checkpoint writes the whole map to the snapshot file and starts a new log. It also repairs the log
+// after a failed record couldn't be cut off it.
+// The complexity is O(n)
+func (d *Durable[Map, K, V]) Checkpoint() error {
+	data, err := d.sm.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	// the new snapshot replaces the old one only when it's completely on the disk
+	tmp := filepath.Join(d.dir, durableSnapshotFile+".tmp")
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(d.dir, durableSnapshotFile)); err != nil {
+		return err
+	}
+	if err := syncDir(d.dir); err != nil {
+		return err
+	}
+
+	// a crash before the log is cleared only makes the records be replayed over the snapshot which has them
+	if err := d.wal.Truncate(0); err != nil {
+		return err
+	}
+	// the snapshot has all the changes, so a log which was broken is good again
+	d.records, d.size, d.err = 0, 0, nil
+
+	return d.wal.Sync()
+}
+
+// Close flushes the log and closes it. The map must not be changed after that.
+func (d *Durable[Map, K, V]) Close() error {
+	if err := d.wal.Sync(); err != nil {
+		_ = d.wal.Close()
+		return err
+	}
+
+	return d.wal.Close()
+}
+
+// log appends a record to the log. A record is the length and the checksum of the payload followed by
+// the payload: the kind of the change and the gob encoding of the pair.
+func (d *Durable[Map, K, V]) log(op byte, kv KV[K, V]) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(make([]byte, walHeaderSize))
+	buf.WriteByte(op)
+	if err := gob.NewEncoder(&buf).Encode(kv); err != nil {
+		return err
+	}
+	rec := buf.Bytes()
+	payload := rec[walHeaderSize:]
+	binary.LittleEndian.PutUint32(rec[0:4], uint32(len(payload))) //nolint:gosec // a pair is never that large
+	binary.LittleEndian.PutUint32(rec[4:8], crc32.Checksum(payload, walCRC))
+
+	_, err := d.wal.Write(rec)
+	if err == nil && d.o.sync == SyncAlways {
+		err = d.wal.Sync()
+	}
+	if err != nil {
+		// the record must not be replayed, and a torn one would hide the records appended after it
+		if terr := d.wal.Truncate(d.size); terr != nil {
+			d.err = fmt.Errorf("sortedmap: log is broken, checkpoint to repair it: %w", errors.Join(err, terr))
+		}
+		return err
+	}
+	d.size += int64(len(rec))
+	d.records++
+
+	return nil
+}
+
+// replay applies the records of the log to the map. The log is cut at the first record which is not complete,
+// so the next records are appended right after the last good one.
+func (d *Durable[Map, K, V]) replay() error {
+	if _, err := d.wal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(d.wal)
+	var (
+		off    int64
+		header [walHeaderSize]byte
+	)
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return err
+		}
+		size := binary.LittleEndian.Uint32(header[0:4])
+		if size == 0 || size > walMaxRecord {
+			break
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return err
+		}
+		if crc32.Checksum(payload, walCRC) != binary.LittleEndian.Uint32(header[4:8]) {
+			break
+		}
+
+		var kv KV[K, V]
+		if err := gob.NewDecoder(bytes.NewReader(payload[1:])).Decode(&kv); err != nil {
+			return fmt.Errorf("sortedmap: decode log record at %d: %w", off, err)
+		}
+		switch payload[0] {
+		case walInsert:
+			d.sm.Insert(kv.Key, kv.Val)
+		case walDelete:
+			d.sm.Delete(kv.Key)
+		default:
+			return fmt.Errorf("sortedmap: unknown log record %d at %d", payload[0], off)
+		}
+		off += walHeaderSize + int64(size)
+		d.records++
+	}
+	d.size = off
+
+	return d.wal.Truncate(off)
+}
+
+func (d *Durable[Map, K, V]) maybeCheckpoint() error {
+	if d.o.checkpoint > 0 && d.records >= d.o.checkpoint {
+		if err := d.Checkpoint(); err != nil {
+			return fmt.Errorf("%w: %w", ErrCheckpoint, err)
+		}
+	}
+
+	return nil
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// syncDir flushes the directory entries, so a renamed file stays renamed after a power failure.
+func syncDir(dir string) error {
+	f, err := os.Open(dir) //nolint:gosec // the directory is given by the caller
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,420 @@
+package sortedmap
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func byKeyInt(i, j KV[int, int]) bool {
+	return i.Key < j.Key
+}
+
+func TestDurable(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DurableOption
+	}{
+		{"log only", []DurableOption{WithCheckpointEvery(0)}},
+		{"frequent checkpoints", []DurableOption{WithCheckpointEvery(7)}},
+		{"no sync", []DurableOption{WithSyncPolicy(SyncNever)}},
+		{"skip list", []DurableOption{WithMapOptions(WithStorage(SkipList))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rnd := rand.New(rand.NewSource(42))
+			want := map[int]int{}
+			for round := 0; round < 5; round++ {
+				d, err := OpenDurable[map[int]int](dir, byKeyInt, tt.opts...)
+				if err != nil {
+					t.Fatalf("OpenDurable() error = %v", err)
+				}
+				checkSorted(t, d.sm.CollectAll(), want, byKeyInt)
+
+				for i := 0; i < 100; i++ {
+					key := rnd.Intn(50)
+					if _, ok := want[key]; ok && i%3 == 0 {
+						if v, ok, err := d.Delete(key); err != nil || !ok || *v != want[key] {
+							t.Fatalf("Delete(%d) = %v, %v, %v, want %d, true, nil", key, ptrVal(v), ok, err, want[key])
+						}
+						delete(want, key)
+					} else {
+						want[key] = rnd.Int()
+						if err := d.Insert(key, want[key]); err != nil {
+							t.Fatalf("Insert() error = %v", err)
+						}
+					}
+				}
+				if _, ok, err := d.Delete(-1); ok || err != nil {
+					t.Errorf("Delete(-1) = %v, %v, want false, nil", ok, err)
+				}
+				if round == 2 {
+					if err := d.Checkpoint(); err != nil {
+						t.Fatalf("Checkpoint() error = %v", err)
+					}
+				}
+				if err := d.Close(); err != nil {
+					t.Fatalf("Close() error = %v", err)
+				}
+			}
+
+			d, err := OpenDurable[map[int]int](dir, byKeyInt, tt.opts...)
+			if err != nil {
+				t.Fatalf("OpenDurable() error = %v", err)
+			}
+			defer d.Close()
+			if !maps.Equal(maps.Collect(d.All()), want) || d.Len() != len(want) {
+				t.Errorf("All() = %v, want %v", maps.Collect(d.All()), want)
+			}
+			if !slices.IsSorted(slices.Collect(d.Keys())) {
+				t.Errorf("Keys() are not sorted")
+			}
+			for k, v := range want {
+				if got, ok := d.Get(k); !ok || got != v {
+					t.Errorf("Get(%d) = %d, %v, want %d, true", k, got, ok, v)
+				}
+			}
+			if got := slices.Collect(d.Values()); len(got) != len(want) {
+				t.Errorf("Values() has %d values, want %d", len(got), len(want))
+			}
+			if got := d.Snapshot().CollectAll(); !slices.Equal(got, d.sm.CollectAll()) {
+				t.Errorf("Snapshot() = %v, want %v", got, d.sm.CollectAll())
+			}
+		})
+	}
+}
+
+func TestDurable_recovery(t *testing.T) {
+	dir := t.TempDir()
+	d, err := OpenDurable[map[int]int](dir, byKeyInt, WithCheckpointEvery(0), WithSyncPolicy(SyncNever))
+	if err != nil {
+		t.Fatalf("OpenDurable() error = %v", err)
+	}
+	// a snapshot with some pairs, then a log on top of it
+	for i := 0; i < 10; i++ {
+		if err := d.Insert(i, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	// states[i] is the content of the map when the log is ends[i] bytes long
+	states, ends := []map[int]int{d.sm.Collect()}, []int64{0}
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 30; i++ {
+		key := rnd.Intn(20)
+		if i%4 == 0 {
+			if _, ok := d.Get(key); !ok {
+				continue
+			}
+			if _, _, err := d.Delete(key); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := d.Insert(key, 100+i); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(filepath.Join(dir, durableWALFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		states, ends = append(states, d.sm.Collect()), append(ends, fi.Size())
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := os.ReadFile(filepath.Join(dir, durableSnapshotFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal, err := os.ReadFile(filepath.Join(dir, durableWALFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restore := func(t *testing.T, log []byte) *Durable[map[int]int, int, int] {
+		t.Helper()
+		crashed := t.TempDir()
+		if err := os.WriteFile(filepath.Join(crashed, durableSnapshotFile), snapshot, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(crashed, durableWALFile), log, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		d, err := OpenDurable[map[int]int](crashed, byKeyInt, WithSyncPolicy(SyncNever))
+		if err != nil {
+			t.Fatalf("OpenDurable() error = %v", err)
+		}
+		t.Cleanup(func() { _ = d.Close() })
+
+		return d
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		// around every record boundary and at random offsets inside the records
+		var offsets []int64
+		for _, end := range ends {
+			offsets = append(offsets, max(end-1, 0), end, min(end+1, int64(len(wal))))
+		}
+		for i := 0; i < 50; i++ {
+			offsets = append(offsets, rnd.Int63n(int64(len(wal))))
+		}
+		for _, off := range offsets {
+			// the last complete record tells the state
+			i := len(ends) - 1
+			for ends[i] > off {
+				i--
+			}
+			d := restore(t, wal[:off])
+			if got := d.sm.Collect(); !maps.Equal(got, states[i]) {
+				t.Fatalf("log cut at %d: got %v, want %v", off, got, states[i])
+			}
+
+			// new records go right after the last complete one
+			if err := d.Insert(1000, 1); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+			reopened, err := OpenDurable[map[int]int](d.dir, byKeyInt, WithSyncPolicy(SyncNever))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := maps.Clone(states[i])
+			want[1000] = 1
+			if got := reopened.sm.Collect(); !maps.Equal(got, want) {
+				t.Fatalf("log cut at %d and appended: got %v, want %v", off, got, want)
+			}
+			_ = reopened.Close()
+		}
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		for _, i := range []int{0, len(ends) / 2, len(ends) - 2} {
+			log := slices.Clone(wal)
+			// flip a byte in the payload of the record which follows ends[i]
+			log[ends[i]+walHeaderSize+1] ^= 0xff
+			if got := restore(t, log).sm.Collect(); !maps.Equal(got, states[i]) {
+				t.Fatalf("record after %d corrupted: got %v, want %v", ends[i], got, states[i])
+			}
+		}
+	})
+}
+
+func TestOpenDurable_errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, durableSnapshotFile), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDurable[map[int]int](dir, byKeyInt); err == nil {
+		t.Errorf("OpenDurable() with a broken snapshot error = nil, want an error")
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDurable[map[int]int](file, byKeyInt); err == nil {
+		t.Errorf("OpenDurable() on a file error = nil, want an error")
+	}
+}
+
+// faultyWAL fails the next Write after writing half of the record, the next Sync or the next Truncate.
+type faultyWAL struct {
+	walFile
+	failWrite, failSync, failTruncate bool
+}
+
+func (f *faultyWAL) Write(p []byte) (int, error) {
+	if f.failWrite {
+		f.failWrite = false
+		n, _ := f.walFile.Write(p[:len(p)/2])
+		return n, os.ErrClosed
+	}
+
+	return f.walFile.Write(p)
+}
+
+func (f *faultyWAL) Sync() error {
+	if f.failSync {
+		f.failSync = false
+		return os.ErrClosed
+	}
+
+	return f.walFile.Sync()
+}
+
+func (f *faultyWAL) Truncate(size int64) error {
+	if f.failTruncate {
+		f.failTruncate = false
+		return os.ErrClosed
+	}
+
+	return f.walFile.Truncate(size)
+}
+
+func TestDurable_logErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		fault  faultyWAL
+		broken bool
+	}{
+		{name: "torn write", fault: faultyWAL{failWrite: true}},
+		{name: "failed sync", fault: faultyWAL{failSync: true}},
+		{name: "torn write not cut off", fault: faultyWAL{failWrite: true, failTruncate: true}, broken: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			d, err := OpenDurable[map[int]int](dir, byKeyInt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Insert(1, 1); err != nil {
+				t.Fatal(err)
+			}
+			f := tt.fault
+			f.walFile = d.wal
+			d.wal = &f
+
+			if err := d.Insert(2, 2); err == nil || errors.Is(err, ErrCheckpoint) {
+				t.Fatalf("Insert() error = %v, want a log error", err)
+			}
+			if _, ok := d.Get(2); ok {
+				t.Errorf("Get(2) after a failed Insert() = true, want false")
+			}
+
+			err = d.Insert(3, 3)
+			if tt.broken {
+				if err == nil {
+					t.Fatalf("Insert() into a broken log error = nil, want an error")
+				}
+				if _, _, err := d.Delete(1); err == nil {
+					t.Fatalf("Delete() from a broken log error = nil, want an error")
+				}
+				if err := d.Checkpoint(); err != nil {
+					t.Fatalf("Checkpoint() error = %v", err)
+				}
+				err = d.Insert(3, 3)
+			}
+			if err != nil {
+				t.Fatalf("Insert() error = %v", err)
+			}
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// the failed record is not replayed and doesn't hide the ones after it
+			d, err = OpenDurable[map[int]int](dir, byKeyInt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.Close()
+			if got, want := d.sm.CollectAll(), []KV[int, int]{{1, 1}, {3, 3}}; !slices.Equal(got, want) {
+				t.Errorf("restored map = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDurable_checkpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	d, err := OpenDurable[map[int]int](dir, byKeyInt, WithCheckpointEvery(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the snapshot can't be written over a directory
+	tmp := filepath.Join(dir, durableSnapshotFile+".tmp")
+	if err := os.MkdirAll(filepath.Join(tmp, "x"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Insert(1, 1); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := d.Insert(2, 2); !errors.Is(err, ErrCheckpoint) {
+		t.Fatalf("Insert() error = %v, want ErrCheckpoint", err)
+	}
+	if _, existed, err := d.Delete(1); !errors.Is(err, ErrCheckpoint) || !existed {
+		t.Fatalf("Delete() = %v, %v, want true, ErrCheckpoint", existed, err)
+	}
+	// the changes are applied despite the errors
+	if got, want := d.sm.CollectAll(), []KV[int, int]{{2, 2}}; !slices.Equal(got, want) {
+		t.Errorf("map = %v, want %v", got, want)
+	}
+
+	if err := os.RemoveAll(tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Insert(3, 3); err != nil {
+		t.Fatalf("Insert() after the snapshot is writable error = %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err = OpenDurable[map[int]int](dir, byKeyInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if got, want := d.sm.CollectAll(), []KV[int, int]{{2, 2}, {3, 3}}; !slices.Equal(got, want) {
+		t.Errorf("restored map = %v, want %v", got, want)
+	}
+}
+
+func ExampleOpenDurable() {
+	dir, _ := os.MkdirTemp("", "sortedmap")
+	defer os.RemoveAll(dir)
+	less := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+
+	d, _ := OpenDurable[map[string]int](dir, less)
+	_ = d.Insert("Bob", 42)
+	_ = d.Insert("Alice", 30)
+	_ = d.Close()
+
+	// after a restart
+	d, _ = OpenDurable[map[string]int](dir, less)
+	defer d.Close()
+	for k, v := range d.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// Alice 30
+	// Bob 42
+}
+
+func BenchmarkDurable_Insert(b *testing.B) {
+	policies := []struct {
+		name string
+		p    SyncPolicy
+	}{
+		{"SyncAlways", SyncAlways},
+		{"SyncNever", SyncNever},
+	}
+	for _, p := range policies {
+		b.Run(p.name, func(b *testing.B) {
+			d, err := OpenDurable[map[int]int](b.TempDir(), byKeyInt, WithSyncPolicy(p.p))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer d.Close()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := d.Insert(i%1000, i); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
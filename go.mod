@@ -1,3 +1,3 @@
 module github.com/egregors/sortedmap
 
-go 1.23
+go 1.24
@@ -0,0 +1,86 @@
+package sortedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNoLess is returned on decoding into a SortedMap which has no comparison function, e.g. a zero value.
+var ErrNoLess = errors.New("sortedmap: less function is required, create the map with New")
+
+// MarshalJSON implements json.Marshaler. The map is written as a JSON object which members are in the order
+// of the map, the keys are encoded the same way encoding/json encodes keys of a regular map.
+// With the WithJSONPairs option the map is written as an array of KV instead.
+func (sm *SortedMap[Map, K, V]) MarshalJSON() ([]byte, error) {
+	if sm.o.jsonPairs {
+		return json.Marshal(sm.CollectAll())
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	// every member is encoded as a map of its own, so encoding/json takes care of the key
+	member := make(Map, 1)
+	for k, v := range sm.All() {
+		clear(member)
+		member[k] = v
+		b, err := json.Marshal(member)
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(b[1 : len(b)-1])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts both a JSON object and an array of KV, and inserts
+// the pairs into the map like encoding/json does for a regular map: the pairs which are already in the map
+// are kept. The map has to be created with New first, since a zero value has no comparison function.
+func (sm *SortedMap[Map, K, V]) UnmarshalJSON(data []byte) error {
+	if sm.less == nil {
+		return ErrNoLess
+	}
+
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		var pairs []KV[K, V]
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return err
+		}
+		for _, el := range pairs {
+			sm.Insert(el.Key, el.Val)
+		}
+
+		return nil
+	}
+
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	for k, v := range m {
+		sm.Insert(k, v)
+	}
+
+	return nil
+}
+
+// NewFromJSON creates a new SortedMap with `less` as the comparison function and populates it with the pairs
+// decoded from `data`, see UnmarshalJSON.
+// The complexity is O(n log n)
+func NewFromJSON[Map ~map[K]V, K comparable, V any](
+	data []byte,
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) (*SortedMap[Map, K, V], error) {
+	sm := New[Map, K, V](less, opts...)
+	if err := sm.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	return sm, nil
+}
@@ -0,0 +1,178 @@
+package sortedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSortedMap_MarshalJSON(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	tests := []struct {
+		name string
+		data map[string]int
+		opts []Option
+		want string
+	}{
+		{
+			name: "object in the order",
+			data: map[string]int{"Bob": 42, "Alice": 30, "Charlie": 25},
+			want: `{"Charlie":25,"Alice":30,"Bob":42}`,
+		},
+		{
+			name: "empty object",
+			data: map[string]int{},
+			want: `{}`,
+		},
+		{
+			name: "escaped keys",
+			data: map[string]int{`"quoted"`: 1, "<tag>": 2},
+			want: `{"\"quoted\"":1,"\u003ctag\u003e":2}`,
+		},
+		{
+			name: "pairs",
+			data: map[string]int{"Bob": 42, "Alice": 30},
+			opts: []Option{WithJSONPairs()},
+			want: `[{"Key":"Alice","Val":30},{"Key":"Bob","Val":42}]`,
+		},
+		{
+			name: "empty pairs",
+			data: map[string]int{},
+			opts: []Option{WithJSONPairs()},
+			want: `[]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewFromMap(tt.data, byVal, tt.opts...)
+			got, err := json.Marshal(sm)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+
+			back, err := NewFromJSON[map[string]int](got, byVal)
+			if err != nil {
+				t.Fatalf("NewFromJSON() error = %v", err)
+			}
+			if !slices.Equal(back.CollectAll(), sm.CollectAll()) {
+				t.Errorf("NewFromJSON() = %v, want %v", back.CollectAll(), sm.CollectAll())
+			}
+		})
+	}
+}
+
+func TestSortedMap_MarshalJSON_keys(t *testing.T) {
+	ints := NewFromMap(map[int]string{10: "ten", 2: "two", -1: "minus one"}, func(i, j KV[int, string]) bool {
+		return i.Key < j.Key
+	})
+	got, err := json.Marshal(ints)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"-1":"minus one","2":"two","10":"ten"}`; string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	// keys which can't be JSON object names are fine as pairs only
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	less := func(i, j KV[[2]int, int]) bool { return i.Key[0] < j.Key[0] }
+	arrays := NewFromMap(map[[2]int]int{{1, 2}: 3}, less)
+	if _, err := json.Marshal(arrays); err == nil {
+		t.Errorf("Marshal() error = nil, want an error for unsupported keys")
+	}
+	arrays = NewFromMap(map[[2]int]int{{1, 2}: 3}, less, WithJSONPairs())
+	if got, err := json.Marshal(arrays); err != nil || string(got) != `[{"Key":[1,2],"Val":3}]` {
+		t.Errorf("Marshal() = %s, %v, want [{\"Key\":[1,2],\"Val\":3}]", got, err)
+	}
+
+	// text marshalers are used for the keys
+	times := NewFromMap(map[time.Time]int{day: 1}, func(i, j KV[time.Time, int]) bool {
+		return i.Key.Before(j.Key)
+	})
+	if got, err := json.Marshal(times); err != nil || string(got) != `{"2025-01-02T00:00:00Z":1}` {
+		t.Errorf("Marshal() = %s, %v, want {\"2025-01-02T00:00:00Z\":1}", got, err)
+	}
+}
+
+func TestSortedMap_UnmarshalJSON(t *testing.T) {
+	less := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    []KV[string, int]
+		wantErr bool
+	}{
+		{"object", `{"Bob":42,"Alice":30}`, []KV[string, int]{{"Alice", 30}, {"Bob", 42}, {"Eve", 84}}, false},
+		{"pairs", ` [{"Key":"Bob","Val":1}]`, []KV[string, int]{{"Bob", 1}, {"Eve", 84}}, false},
+		{"overwrite", `{"Eve":1}`, []KV[string, int]{{"Eve", 1}}, false},
+		{"null", `null`, []KV[string, int]{{"Eve", 84}}, false},
+		{"wrong value", `{"Bob":"42"}`, nil, true},
+		{"wrong pairs", `[1, 2]`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := New[map[string]int](less)
+			sm.Insert("Eve", 84)
+			err := json.Unmarshal([]byte(tt.data), sm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(sm.CollectAll(), tt.want) {
+				t.Errorf("Unmarshal() = %v, want %v", sm.CollectAll(), tt.want)
+			}
+		})
+	}
+
+	var zero SortedMap[map[string]int, string, int]
+	if err := json.Unmarshal([]byte(`{}`), &zero); !errors.Is(err, ErrNoLess) {
+		t.Errorf("Unmarshal() into a zero value error = %v, want %v", err, ErrNoLess)
+	}
+	if _, err := NewFromJSON[map[string]int]([]byte(`{`), less); err == nil {
+		t.Errorf("NewFromJSON() error = nil, want an error")
+	}
+}
+
+func ExampleSortedMap_MarshalJSON() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	})
+
+	data, _ := json.Marshal(sm)
+	fmt.Println(string(data))
+	// Output: {"Charlie":25,"Alice":30,"Bob":42}
+}
+
+func ExampleNewFromJSON() {
+	sm, err := NewFromJSON[map[string]int]([]byte(`{"Bob":42,"Alice":30}`), func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(sm.CollectKeys())
+	// Output: [Alice Bob]
+}
+
+func BenchmarkSortedMap_MarshalJSON(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(sm)
+	}
+}
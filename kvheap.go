@@ -1,27 +1,242 @@
 package sortedmap
 
-// KV is a key-value pair.
-type KV[K comparable, V any] struct {
-	Key K
-	Val V
-}
+import (
+	"container/heap"
+	"iter"
+	"slices"
+)
 
+// kvHeap is a binary min-heap of key-value pairs. It keeps the position of every key
+// inside xs up to date in idx, so an element can be found by key in O(1).
 type kvHeap[K comparable, V any] struct {
 	xs     []KV[K, V]
+	idx    map[K]int
 	lessFn func(i, j KV[K, V]) bool
 }
 
 func newKvHeap[K comparable, V any](less func(i, j KV[K, V]) bool) *kvHeap[K, V] {
 	return &kvHeap[K, V]{
 		xs:     []KV[K, V]{},
+		idx:    map[K]int{},
 		lessFn: less,
 	}
 }
 
+// Insert adds the pair to the heap.
+// The complexity is O(log n)
+func (k *kvHeap[K, V]) Insert(kv KV[K, V]) {
+	heap.Push(k, kv)
+}
+
+// load replaces the content of the heap with the pairs. Pairs in ascending order are already a valid heap.
+// The complexity is O(n)
+func (k *kvHeap[K, V]) load(items []KV[K, V]) {
+	k.xs = slices.Clone(items)
+	clear(k.idx)
+	for i, kv := range k.xs {
+		k.idx[kv.Key] = i
+	}
+}
+
+// heapify replaces the content of the heap with the pairs in any order.
+// The complexity is O(n)
+func (k *kvHeap[K, V]) heapify(items []KV[K, V]) {
+	k.load(items)
+	heap.Init(k)
+}
+
+// heapRebuildRatio is how many times smaller than the heap a batch can be to still restore the heap at once.
+// A smaller batch is applied pair by pair: a push takes O(1) on average, and restoring the heap walks all of it.
+const heapRebuildRatio = 2
+
+// insertMany adds the pairs to the heap. A large batch is appended, and the heap is restored at once.
+// The complexity is O(n + k), O(k log n) for a small batch
+func (k *kvHeap[K, V]) insertMany(items []KV[K, V]) {
+	if len(items)*heapRebuildRatio < len(k.xs) {
+		for _, kv := range items {
+			heap.Push(k, kv)
+		}
+		return
+	}
+
+	for _, kv := range items {
+		k.idx[kv.Key] = len(k.xs)
+		k.xs = append(k.xs, kv)
+	}
+	heap.Init(k)
+}
+
+// deleteMany removes the pairs with the same keys from the heap. For a large batch every pair is replaced
+// by the last one, and the heap is restored at once.
+// The complexity is O(n + k), O(k log n) for a small batch
+func (k *kvHeap[K, V]) deleteMany(items []KV[K, V]) {
+	if len(items)*heapRebuildRatio < len(k.xs) {
+		for _, kv := range items {
+			k.Delete(kv)
+		}
+		return
+	}
+
+	for _, kv := range items {
+		i, ok := k.idx[kv.Key]
+		if !ok {
+			continue
+		}
+		last := len(k.xs) - 1
+		k.Swap(i, last)
+		k.xs[last] = KV[K, V]{}
+		k.xs = k.xs[:last]
+		delete(k.idx, kv.Key)
+	}
+	heap.Init(k)
+}
+
+// Delete removes the pair with the same key from the heap.
+// The complexity is O(log n)
+func (k *kvHeap[K, V]) Delete(kv KV[K, V]) bool {
+	i, ok := k.idx[kv.Key]
+	if !ok {
+		return false
+	}
+	heap.Remove(k, i)
+
+	return true
+}
+
+// All returns a sequence of the pairs in ascending order. The heap itself is never changed:
+// the next pair is always one of the children of the already visited ones, so only those are kept sorted.
+// The complexity is O(k log k) for the first k pairs.
+func (k *kvHeap[K, V]) All() iter.Seq[KV[K, V]] {
+	return func(yield func(KV[K, V]) bool) {
+		c := &heapCursor[K, V]{h: k}
+		if len(k.xs) > 0 {
+			c.next = append(c.next, 0)
+		}
+		for len(c.next) > 0 {
+			i := heap.Pop(c).(int)
+			if i >= len(k.xs) {
+				// the heap has shrunk during the iteration
+				continue
+			}
+			if !yield(k.xs[i]) {
+				return
+			}
+			for _, child := range [...]int{2*i + 1, 2*i + 2} {
+				if child < len(k.xs) {
+					heap.Push(c, child)
+				}
+			}
+		}
+	}
+}
+
+// Backward returns a sequence of the pairs in descending order.
+// The complexity is O(n log n) for the whole traversal.
+func (k *kvHeap[K, V]) Backward() iter.Seq[KV[K, V]] {
+	return func(yield func(KV[K, V]) bool) {
+		xs := slices.Collect(k.All())
+		for i := len(xs) - 1; i >= 0; i-- {
+			if !yield(xs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Seek returns a sequence of the pairs in ascending order, starting from the first one which is not less than probe.
+// The complexity is O(n log n) for the whole traversal.
+func (k *kvHeap[K, V]) Seek(probe KV[K, V]) iter.Seq[KV[K, V]] {
+	return func(yield func(KV[K, V]) bool) {
+		for kv := range k.All() {
+			if !k.lessFn(kv, probe) && !yield(kv) {
+				return
+			}
+		}
+	}
+}
+
+// SeekBackward returns a sequence of the pairs in descending order, starting from the last one which is not
+// greater than probe.
+// The complexity is O(n log n)
+func (k *kvHeap[K, V]) SeekBackward(probe KV[K, V]) iter.Seq[KV[K, V]] {
+	return func(yield func(KV[K, V]) bool) {
+		var xs []KV[K, V]
+		for kv := range k.All() {
+			if k.lessFn(probe, kv) {
+				break
+			}
+			xs = append(xs, kv)
+		}
+		for i := len(xs) - 1; i >= 0; i-- {
+			if !yield(xs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// AllFrom returns a sequence of the pairs in ascending order, starting from the i-th one.
+// The complexity is O(n log n)
+func (k *kvHeap[K, V]) AllFrom(i int) iter.Seq[KV[K, V]] {
+	return skipItems(k.All(), i)
+}
+
+// Rank returns the position of the pair with the same key in the order, or -1 if there is no such pair.
+// The complexity is O(n log n)
+func (k *kvHeap[K, V]) Rank(kv KV[K, V]) int {
+	if _, ok := k.idx[kv.Key]; !ok {
+		return -1
+	}
+
+	return rankOf(k.All(), kv, sameKey[K, V])
+}
+
+// At returns the i-th pair in the order and false if there is no such pair.
+// The complexity is O(n log n)
+func (k *kvHeap[K, V]) At(i int) (KV[K, V], bool) {
+	return itemAt(k.All(), i)
+}
+
+// Min returns the root of the heap and false if the heap is empty.
+// The complexity is O(1)
+func (k *kvHeap[K, V]) Min() (KV[K, V], bool) {
+	if len(k.xs) == 0 {
+		return KV[K, V]{}, false
+	}
+
+	return k.xs[0], true
+}
+
+// Max returns the greatest pair and false if the heap is empty. It has to be one of the leaves.
+// The complexity is O(n)
+func (k *kvHeap[K, V]) Max() (KV[K, V], bool) {
+	if len(k.xs) == 0 {
+		return KV[K, V]{}, false
+	}
+	maxKV := k.xs[len(k.xs)/2]
+	for _, kv := range k.xs[len(k.xs)/2+1:] {
+		if k.lessFn(maxKV, kv) {
+			maxKV = kv
+		}
+	}
+
+	return maxKV, true
+}
+
 func (k *kvHeap[K, V]) Len() int           { return len(k.xs) }
-func (k *kvHeap[K, V]) Swap(i, j int)      { k.xs[i], k.xs[j] = k.xs[j], k.xs[i] }
 func (k *kvHeap[K, V]) Less(i, j int) bool { return k.lessFn(k.xs[i], k.xs[j]) }
-func (k *kvHeap[K, V]) Push(x any)         { k.xs = append(k.xs, x.(KV[K, V])) }
+func (k *kvHeap[K, V]) Swap(i, j int) {
+	k.xs[i], k.xs[j] = k.xs[j], k.xs[i]
+	k.idx[k.xs[i].Key] = i
+	k.idx[k.xs[j].Key] = j
+}
+
+func (k *kvHeap[K, V]) Push(x any) {
+	kv := x.(KV[K, V])
+	k.idx[kv.Key] = len(k.xs)
+	k.xs = append(k.xs, kv)
+}
+
 func (k *kvHeap[K, V]) Pop() any {
 	n := len(k.xs)
 	if n == 0 {
@@ -29,6 +244,33 @@ func (k *kvHeap[K, V]) Pop() any {
 	}
 	x := k.xs[n-1]
 	k.xs = k.xs[:n-1]
+	delete(k.idx, x.Key)
+
+	return x
+}
+
+// heapCursor is a min-heap of positions in the kvHeap which are ready to be visited in order.
+type heapCursor[K comparable, V any] struct {
+	h    *kvHeap[K, V]
+	next []int
+}
+
+func (c *heapCursor[K, V]) Len() int      { return len(c.next) }
+func (c *heapCursor[K, V]) Swap(i, j int) { c.next[i], c.next[j] = c.next[j], c.next[i] }
+func (c *heapCursor[K, V]) Push(x any)    { c.next = append(c.next, x.(int)) }
+func (c *heapCursor[K, V]) Less(i, j int) bool {
+	a, b := c.next[i], c.next[j]
+	if n := c.h.Len(); a >= n || b >= n {
+		// positions past the end were removed during the iteration, let them out first to be skipped
+		return a >= n
+	}
+
+	return c.h.Less(a, b)
+}
+
+func (c *heapCursor[K, V]) Pop() any {
+	x := c.next[len(c.next)-1]
+	c.next = c.next[:len(c.next)-1]
 
 	return x
 }
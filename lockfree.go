@@ -0,0 +1,276 @@
+package sortedmap
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+// LockFreeSortedMap is a sorted map which is safe for concurrent use without locks. It's a skip list where
+// every link is changed by compare-and-swap, so goroutines never wait for each other and many of them can
+// insert at the same time.
+//
+// Unlike SortedMap, it's always ordered by key: moving a pair when its value changes can't be done atomically
+// without a lock. Iteration is weakly consistent: it never yields a key twice and never stops on a concurrent
+// change, but it may or may not see the changes made after it started.
+type LockFreeSortedMap[K comparable, V any] struct {
+	head *lfNode[K, V]
+	// level is the highest level which has ever been used, it only grows
+	level atomic.Int32
+	len   atomic.Int64
+	less  func(i, j K) bool
+}
+
+type lfNode[K comparable, V any] struct {
+	key K
+	// val is nil once the node is logically deleted
+	val  atomic.Pointer[V]
+	next []atomic.Pointer[lfLink[K, V]]
+}
+
+// lfLink is an immutable link to the next node. The deleted node marks its own links, so nothing
+// can be linked after it anymore.
+type lfLink[K comparable, V any] struct {
+	node   *lfNode[K, V]
+	marked bool
+}
+
+// NewLockFree creates a new LockFreeSortedMap with `less` as the comparison function of the keys
+// The complexity is O(1)
+func NewLockFree[K comparable, V any](less func(i, j K) bool) *LockFreeSortedMap[K, V] {
+	if less == nil {
+		panic("less function is required")
+	}
+	lm := &LockFreeSortedMap[K, V]{
+		head: newLfNode[K, V](skipListMaxLevel),
+		less: less,
+	}
+	lm.level.Store(1)
+
+	return lm
+}
+
+func newLfNode[K comparable, V any](level int) *lfNode[K, V] {
+	n := &lfNode[K, V]{next: make([]atomic.Pointer[lfLink[K, V]], level)}
+	for l := range n.next {
+		n.next[l].Store(&lfLink[K, V]{})
+	}
+
+	return n
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists in the map
+// The complexity is O(log n) on average
+func (lm *LockFreeSortedMap[K, V]) Get(key K) (V, bool) {
+	x := lm.head
+	var curr *lfNode[K, V]
+	for l := int(lm.level.Load()) - 1; l >= 0; l-- {
+		curr = x.next[l].Load().node
+		for curr != nil {
+			next := curr.next[l].Load()
+			switch {
+			case next.marked:
+				curr = next.node
+				continue
+			case lm.less(curr.key, key):
+				x, curr = curr, next.node
+				continue
+			}
+			break
+		}
+	}
+	if curr != nil && !lm.less(key, curr.key) {
+		if v := curr.val.Load(); v != nil {
+			return *v, true
+		}
+	}
+
+	return *new(V), false
+}
+
+// Insert adds a key-value pair to the map. If the key already exists, the value is updated
+// The complexity is O(log n) on average
+func (lm *LockFreeSortedMap[K, V]) Insert(key K, val V) {
+	var (
+		preds [skipListMaxLevel]*lfNode[K, V]
+		links [skipListMaxLevel]*lfLink[K, V]
+	)
+	for {
+		if n := lm.find(key, &preds, &links); n != nil {
+			if old := n.val.Load(); old != nil {
+				if n.val.CompareAndSwap(old, &val) {
+					return
+				}
+				continue
+			}
+			// the node is being deleted, help to unlink it and try again
+			lm.mark(n)
+			continue
+		}
+
+		level := randomLevel()
+		lm.raiseLevel(level)
+		n := newLfNode[K, V](level)
+		n.key = key
+		n.val.Store(&val)
+		for l := 0; l < level; l++ {
+			n.next[l].Store(&lfLink[K, V]{node: links[l].node})
+		}
+		if !preds[0].next[0].CompareAndSwap(links[0], &lfLink[K, V]{node: n}) {
+			continue
+		}
+		lm.len.Add(1)
+
+		for l := 1; l < level; l++ {
+			for {
+				next := n.next[l].Load()
+				if next.marked {
+					// the node has already been deleted, no need to link it further
+					return
+				}
+				if next.node != links[l].node {
+					if !n.next[l].CompareAndSwap(next, &lfLink[K, V]{node: links[l].node}) {
+						continue
+					}
+				}
+				if preds[l].next[l].CompareAndSwap(links[l], &lfLink[K, V]{node: n}) {
+					break
+				}
+				if lm.find(key, &preds, &links) != n {
+					return
+				}
+			}
+		}
+
+		return
+	}
+}
+
+// Delete removes the key from the map and returns the value associated with the key and a boolean indicating
+// if the key existed in the map.
+// The complexity is O(log n) on average
+func (lm *LockFreeSortedMap[K, V]) Delete(key K) (val *V, existed bool) {
+	var (
+		preds [skipListMaxLevel]*lfNode[K, V]
+		links [skipListMaxLevel]*lfLink[K, V]
+	)
+	n := lm.find(key, &preds, &links)
+	if n == nil {
+		return (*V)(nil), false
+	}
+	for {
+		v := n.val.Load()
+		if v == nil {
+			// someone else has deleted it first
+			return (*V)(nil), false
+		}
+		if n.val.CompareAndSwap(v, nil) {
+			lm.len.Add(-1)
+			lm.mark(n)
+			lm.find(key, &preds, &links)
+
+			return v, true
+		}
+	}
+}
+
+// All returns a sequence of key-value pairs in the order of the keys
+func (lm *LockFreeSortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := lm.head.next[0].Load().node; n != nil; n = n.next[0].Load().node {
+			if v := n.val.Load(); v != nil && !yield(n.key, *v) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns a sequence of keys in the order
+func (lm *LockFreeSortedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range lm.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of values in the order of the keys
+func (lm *LockFreeSortedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range lm.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of the pairs in the map
+func (lm *LockFreeSortedMap[K, V]) Len() int {
+	return int(lm.len.Load())
+}
+
+// find fills preds with the last nodes on every level which keys are less than the key, and links with
+// their links to the next nodes. Marked nodes on the way are unlinked. It returns the node with the key or nil.
+func (lm *LockFreeSortedMap[K, V]) find(
+	key K,
+	preds *[skipListMaxLevel]*lfNode[K, V],
+	links *[skipListMaxLevel]*lfLink[K, V],
+) *lfNode[K, V] {
+	top := int(lm.level.Load())
+	for l := top; l < skipListMaxLevel; l++ {
+		preds[l], links[l] = lm.head, lm.head.next[l].Load()
+	}
+retry:
+	x := lm.head
+	for l := top - 1; l >= 0; l-- {
+		link := x.next[l].Load()
+		if link.marked {
+			goto retry
+		}
+		for link.node != nil {
+			next := link.node.next[l].Load()
+			if next.marked {
+				unlinked := &lfLink[K, V]{node: next.node}
+				if !x.next[l].CompareAndSwap(link, unlinked) {
+					goto retry
+				}
+				link = unlinked
+				continue
+			}
+			if !lm.less(link.node.key, key) {
+				break
+			}
+			x, link = link.node, next
+		}
+		preds[l], links[l] = x, link
+	}
+	if n := links[0].node; n != nil && !lm.less(key, n.key) {
+		return n
+	}
+
+	return nil
+}
+
+// mark marks all the links of the node from top to bottom, so nothing can be linked after it.
+func (lm *LockFreeSortedMap[K, V]) mark(n *lfNode[K, V]) {
+	for l := len(n.next) - 1; l >= 0; l-- {
+		for {
+			next := n.next[l].Load()
+			if next.marked || n.next[l].CompareAndSwap(next, &lfLink[K, V]{node: next.node, marked: true}) {
+				break
+			}
+		}
+	}
+}
+
+// raiseLevel makes sure searches start at least from the given level.
+func (lm *LockFreeSortedMap[K, V]) raiseLevel(level int) {
+	for {
+		cur := lm.level.Load()
+		if int(cur) >= level || lm.level.CompareAndSwap(cur, int32(level)) { //nolint:gosec // level is at most 32
+			return
+		}
+	}
+}
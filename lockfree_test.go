@@ -0,0 +1,183 @@
+package sortedmap
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLockFreeSortedMap(t *testing.T) {
+	lm := NewLockFree[string, int](func(i, j string) bool { return i < j })
+	for _, kv := range []KV[string, int]{{"Bob", 42}, {"Alice", 30}, {"Charlie", 25}, {"Bob", 43}} {
+		lm.Insert(kv.Key, kv.Val)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantVal int
+		wantOk  bool
+	}{
+		{"existing key", "Alice", 30, true},
+		{"updated key", "Bob", 43, true},
+		{"missing key", "Eve", 0, false},
+		{"missing first key", "A", 0, false},
+		{"missing last key", "Z", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, ok := lm.Get(tt.key); v != tt.wantVal || ok != tt.wantOk {
+				t.Errorf("Get(%v) = %v, %v, want %v, %v", tt.key, v, ok, tt.wantVal, tt.wantOk)
+			}
+		})
+	}
+
+	if got := lm.Len(); got != 3 {
+		t.Errorf("Len() = %v, want 3", got)
+	}
+	if v, ok := lm.Delete("Bob"); !ok || *v != 43 {
+		t.Errorf("Delete(Bob) = %v, %v, want 43, true", ptrVal(v), ok)
+	}
+	if v, ok := lm.Delete("Bob"); ok {
+		t.Errorf("Delete(Bob) = %v, true, want false", ptrVal(v))
+	}
+	if got, want := slices.Collect(lm.Keys()), []string{"Alice", "Charlie"}; !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(lm.Values()), []int{30, 25}; !slices.Equal(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	if got := lm.Len(); got != 2 {
+		t.Errorf("Len() = %v, want 2", got)
+	}
+
+	panicsWithValue(t, "less function is required", func() {
+		NewLockFree[string, int](nil)
+	})
+}
+
+func TestLockFreeSortedMap_race(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 200
+		ops     = 5000
+	)
+	lm := NewLockFree[int, int](func(i, j int) bool { return i < j })
+
+	// every worker owns the keys equal to its number modulo workers, so the final content is known
+	want := make([]map[int]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		want[w] = map[int]int{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < ops; i++ {
+				key := (i*7919)%keys*workers + w
+				if i%3 == 0 {
+					lm.Delete(key)
+					delete(want[w], key)
+				} else {
+					lm.Insert(key, i)
+					want[w][key] = i
+				}
+				if v, ok := lm.Get(key); ok != (i%3 != 0) || ok && v != i {
+					t.Errorf("Get(%d) = %d, %v right after the change", key, v, ok)
+				}
+			}
+		}()
+	}
+	for r := 0; r < workers/2; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < ops/100; i++ {
+				prev := -1
+				for k := range lm.Keys() {
+					if k <= prev {
+						t.Errorf("Keys() is not sorted: %d after %d", k, prev)
+					}
+					prev = k
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	all := map[int]int{}
+	for _, m := range want {
+		for k, v := range m {
+			all[k] = v
+		}
+	}
+	got := make([]KV[int, int], 0, len(all))
+	for k, v := range lm.All() {
+		got = append(got, KV[int, int]{k, v})
+	}
+	checkSorted(t, got, all, func(i, j KV[int, int]) bool { return i.Key < j.Key })
+	if lm.Len() != len(all) {
+		t.Errorf("Len() = %d, want %d", lm.Len(), len(all))
+	}
+}
+
+func ExampleNewLockFree() {
+	lm := NewLockFree[string, int](func(i, j string) bool {
+		return i < j
+	})
+
+	var wg sync.WaitGroup
+	for i, name := range []string{"Charlie", "Alice", "Bob"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lm.Insert(name, i)
+		}()
+	}
+	wg.Wait()
+
+	for k, v := range lm.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// Alice 1
+	// Bob 2
+	// Charlie 0
+}
+
+func BenchmarkLockFreeSortedMap(b *testing.B) {
+	const n = 10_000
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	for _, procs := range []int{1, 2, 4, 8} {
+		runtime.GOMAXPROCS(procs)
+		b.Run(fmt.Sprintf("LockFree/Insert/procs=%d", procs), func(b *testing.B) {
+			lm := NewLockFree[int, int](func(i, j int) bool { return i < j })
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					lm.Insert(i*7919%n, i)
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("Concurrent/Insert/procs=%d", procs), func(b *testing.B) {
+			cm := NewConcurrent[map[int]int](func(i, j KV[int, int]) bool { return i.Key < j.Key })
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					cm.Insert(i*7919%n, i)
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("LockFree/Get/procs=%d", procs), func(b *testing.B) {
+			lm := NewLockFree[int, int](func(i, j int) bool { return i < j })
+			for i := 0; i < n; i++ {
+				lm.Insert(i, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					lm.Get(i * 7919 % n)
+				}
+			})
+		})
+	}
+}
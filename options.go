@@ -0,0 +1,75 @@
+package sortedmap
+
+// Option configures a SortedMap.
+type Option func(*options)
+
+type options struct {
+	storage    Storage
+	jsonPairs  bool
+	presorted  bool
+	duplicates DuplicatePolicy
+}
+
+func newOptions(opts []Option) options {
+	return options{storage: Tree}.with(opts)
+}
+
+// with returns a copy of the options with opts applied on top of them.
+func (o options) with(opts []Option) options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithStorage sets the kind of storage which keeps the map ordered. The default is Tree.
+func WithStorage(s Storage) Option {
+	return func(o *options) {
+		o.storage = s
+	}
+}
+
+// WithJSONPairs makes the map marshal to JSON as an array of {"Key": ..., "Val": ...} objects instead of
+// a JSON object. It keeps the order for any JSON decoder and allows keys which can't be JSON object names.
+func WithJSONPairs() Option {
+	return func(o *options) {
+		o.jsonPairs = true
+	}
+}
+
+// WithPresorted makes NewFromSlice and NewFromSeq trust that the pairs are in the order of the map and skip
+// checking it. The map is broken if they are not.
+func WithPresorted() Option {
+	return func(o *options) {
+		o.presorted = true
+	}
+}
+
+// WithDuplicates sets what NewFromSlice and NewFromSeq do with a repeated key. The default is KeepLast.
+func WithDuplicates(p DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicates = p
+	}
+}
+
+// RangeOption configures bounds of SortedMap.Range.
+type RangeOption func(*rangeOptions)
+
+type rangeOptions struct {
+	excludeFrom, excludeTo bool
+}
+
+// ExcludeFrom leaves the pairs which are equal to the lower bound out of the range.
+func ExcludeFrom() RangeOption {
+	return func(o *rangeOptions) {
+		o.excludeFrom = true
+	}
+}
+
+// ExcludeTo leaves the pairs which are equal to the upper bound out of the range.
+func ExcludeTo() RangeOption {
+	return func(o *rangeOptions) {
+		o.excludeTo = true
+	}
+}
@@ -0,0 +1,233 @@
+package sortedmap
+
+import (
+	"hash/maphash"
+	"iter"
+)
+
+// PersistentSortedMap is an immutable sorted map. Every change returns a new version of the map and leaves
+// the old one as it was, the versions share all the nodes which the change didn't touch.
+// Since no version ever changes, they are safe for concurrent use by multiple goroutines.
+type PersistentSortedMap[Map ~map[K]V, K comparable, V any] struct {
+	// order keeps the pairs sorted by less
+	order *tree[KV[K, V]]
+	// keys keeps the pairs sorted by the hash of the key, so a key can be found in O(log n)
+	keys *tree[hashedKV[K, V]]
+	seed maphash.Seed
+	less func(i, j KV[K, V]) bool
+}
+
+type hashedKV[K comparable, V any] struct {
+	hash uint64
+	kv   KV[K, V]
+}
+
+// NewPersistent creates a new empty PersistentSortedMap with `less` as the comparison function
+// The complexity is O(1)
+func NewPersistent[Map ~map[K]V, K comparable, V any](less func(i, j KV[K, V]) bool) *PersistentSortedMap[Map, K, V] {
+	if less == nil {
+		panic("less function is required")
+	}
+
+	return &PersistentSortedMap[Map, K, V]{
+		order: newTree(less, sameKey[K, V]),
+		keys: newTree(
+			func(a, b hashedKV[K, V]) bool { return a.hash < b.hash },
+			func(a, b hashedKV[K, V]) bool { return a.kv.Key == b.kv.Key },
+		),
+		seed: maphash.MakeSeed(),
+		less: less,
+	}
+}
+
+// NewPersistentFromMap creates a new PersistentSortedMap with `less` as the comparison function
+// and the contents of `m`.
+// The complexity is O(n log n) where n = len(m).
+func NewPersistentFromMap[Map ~map[K]V, K comparable, V any](
+	m Map,
+	less func(i, j KV[K, V]) bool,
+) *PersistentSortedMap[Map, K, V] {
+	pm := NewPersistent[Map, K, V](less).clone()
+	for k, v := range m {
+		pm.set(k, v)
+	}
+
+	return pm
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists in the map
+// The complexity is O(log n)
+func (pm *PersistentSortedMap[Map, K, V]) Get(key K) (V, bool) {
+	if el, ok := pm.find(key); ok {
+		return el.kv.Val, true
+	}
+
+	return *new(V), false
+}
+
+// With returns a new version of the map where the key is associated with the value
+// The complexity is O(log n)
+func (pm *PersistentSortedMap[Map, K, V]) With(key K, val V) *PersistentSortedMap[Map, K, V] {
+	next := pm.clone()
+	next.set(key, val)
+
+	return next
+}
+
+// Without returns a new version of the map without the key, or the same map if there is no such key
+// The complexity is O(log n)
+func (pm *PersistentSortedMap[Map, K, V]) Without(key K) *PersistentSortedMap[Map, K, V] {
+	el, ok := pm.find(key)
+	if !ok {
+		return pm
+	}
+	next := pm.clone()
+	next.order.Delete(el.kv)
+	next.keys.Delete(el)
+
+	return next
+}
+
+// Len returns the number of the pairs in the map
+func (pm *PersistentSortedMap[Map, K, V]) Len() int {
+	return pm.order.Len()
+}
+
+// All returns a sequence of key-value pairs
+func (pm *PersistentSortedMap[Map, K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for el := range pm.order.All() {
+			if !yield(el.Key, el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns a sequence of keys
+func (pm *PersistentSortedMap[Map, K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for el := range pm.order.All() {
+			if !yield(el.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of values
+func (pm *PersistentSortedMap[Map, K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for el := range pm.order.All() {
+			if !yield(el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns a sequence of key-value pairs in the reverse order
+func (pm *PersistentSortedMap[Map, K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for el := range pm.order.Backward() {
+			if !yield(el.Key, el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Min returns the first key-value pair in the order and a boolean indicating if the map is not empty
+// The complexity is O(log n)
+func (pm *PersistentSortedMap[Map, K, V]) Min() (K, V, bool) {
+	kv, ok := pm.order.Min()
+
+	return kv.Key, kv.Val, ok
+}
+
+// Max returns the last key-value pair in the order and a boolean indicating if the map is not empty
+// The complexity is O(log n)
+func (pm *PersistentSortedMap[Map, K, V]) Max() (K, V, bool) {
+	kv, ok := pm.order.Max()
+
+	return kv.Key, kv.Val, ok
+}
+
+// Collect returns a regular map with an *unordered* content off the map
+func (pm *PersistentSortedMap[Map, K, V]) Collect() Map {
+	m := make(Map, pm.Len())
+	for key, val := range pm.All() {
+		m[key] = val
+	}
+
+	return m
+}
+
+// CollectAll returns a slice of key-value pairs
+func (pm *PersistentSortedMap[Map, K, V]) CollectAll() []KV[K, V] {
+	pairs := make([]KV[K, V], 0, pm.Len())
+	for k, v := range pm.All() {
+		pairs = append(pairs, KV[K, V]{k, v})
+	}
+
+	return pairs
+}
+
+// CollectKeys returns a slice of the map’s keys
+func (pm *PersistentSortedMap[Map, K, V]) CollectKeys() []K {
+	ks := make([]K, 0, pm.Len())
+	for k := range pm.Keys() {
+		ks = append(ks, k)
+	}
+
+	return ks
+}
+
+// CollectValues returns a slice of the map's values
+func (pm *PersistentSortedMap[Map, K, V]) CollectValues() []V {
+	vals := make([]V, 0, pm.Len())
+	for val := range pm.Values() {
+		vals = append(vals, val)
+	}
+
+	return vals
+}
+
+// clone returns a new version which shares all the nodes with the map. Only the new version can be changed.
+func (pm *PersistentSortedMap[Map, K, V]) clone() *PersistentSortedMap[Map, K, V] {
+	next := *pm
+	next.order = pm.order.clone()
+	next.keys = pm.keys.clone()
+
+	return &next
+}
+
+// set changes the map in place, so it can only be called on a new version.
+func (pm *PersistentSortedMap[Map, K, V]) set(key K, val V) {
+	if old, ok := pm.find(key); ok {
+		pm.order.Delete(old.kv)
+		pm.keys.Delete(old)
+	}
+	kv := KV[K, V]{key, val}
+	pm.order.Insert(kv)
+	pm.keys.Insert(hashedKV[K, V]{pm.hash(key), kv})
+}
+
+// find returns the pair with the key from the key index.
+func (pm *PersistentSortedMap[Map, K, V]) find(key K) (hashedKV[K, V], bool) {
+	h := pm.hash(key)
+	for el := range pm.keys.Seek(hashedKV[K, V]{hash: h}) {
+		if el.hash != h {
+			break
+		}
+		if el.kv.Key == key {
+			return el, true
+		}
+	}
+
+	return hashedKV[K, V]{}, false
+}
+
+func (pm *PersistentSortedMap[Map, K, V]) hash(key K) uint64 {
+	return maphash.Comparable(pm.seed, key)
+}
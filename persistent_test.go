@@ -0,0 +1,141 @@
+package sortedmap
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPersistentSortedMap(t *testing.T) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Val < j.Val
+	}
+	rnd := rand.New(rand.NewSource(42))
+	versions := []*PersistentSortedMap[map[int]int, int, int]{NewPersistent[map[int]int](less)}
+	wants := []map[int]int{{}}
+	for i := 0; i < 1000; i++ {
+		pm, want := versions[len(versions)-1], maps.Clone(wants[len(wants)-1])
+		key := rnd.Intn(200)
+		if _, ok := want[key]; ok && i%3 == 0 {
+			pm = pm.Without(key)
+			delete(want, key)
+		} else {
+			val := rnd.Intn(50)
+			pm = pm.With(key, val)
+			want[key] = val
+		}
+		versions, wants = append(versions, pm), append(wants, want)
+	}
+
+	// every version is still valid
+	for i, pm := range versions {
+		checkSorted(t, pm.CollectAll(), wants[i], less)
+		if pm.Len() != len(wants[i]) {
+			t.Fatalf("version %d: Len() = %d, want %d", i, pm.Len(), len(wants[i]))
+		}
+		for key := 0; key < 200; key++ {
+			want, wantOk := wants[i][key]
+			if got, ok := pm.Get(key); got != want || ok != wantOk {
+				t.Fatalf("version %d: Get(%d) = %v, %v, want %v, %v", i, key, got, ok, want, wantOk)
+			}
+		}
+	}
+
+	pm := versions[len(versions)-1]
+	if got := pm.Without(-1); got != pm {
+		t.Errorf("Without() of a missing key returned a new version")
+	}
+	all := pm.CollectAll()
+	backward := slices.Collect(func(yield func(KV[int, int]) bool) {
+		for k, v := range pm.Backward() {
+			if !yield(KV[int, int]{k, v}) {
+				return
+			}
+		}
+	})
+	slices.Reverse(backward)
+	if !slices.Equal(all, backward) {
+		t.Errorf("Backward() = %v, want %v reversed", backward, all)
+	}
+	if k, v, ok := pm.Min(); !ok || (KV[int, int]{k, v}) != all[0] {
+		t.Errorf("Min() = %v, %v, %v, want %v", k, v, ok, all[0])
+	}
+	if k, v, ok := pm.Max(); !ok || (KV[int, int]{k, v}) != all[len(all)-1] {
+		t.Errorf("Max() = %v, %v, %v, want %v", k, v, ok, all[len(all)-1])
+	}
+	keys, vals := pm.CollectKeys(), pm.CollectValues()
+	for i, el := range all {
+		if keys[i] != el.Key || vals[i] != el.Val {
+			t.Fatalf("CollectKeys()[%d], CollectValues()[%d] = %v, %v, want %v", i, i, keys[i], vals[i], el)
+		}
+	}
+	if got := pm.Collect(); !maps.Equal(got, wants[len(wants)-1]) {
+		t.Errorf("Collect() = %v, want %v", got, wants[len(wants)-1])
+	}
+
+	panicsWithValue(t, "less function is required", func() {
+		NewPersistent[map[int]int, int, int](nil)
+	})
+}
+
+func TestPersistentSortedMap_race(t *testing.T) {
+	base := NewPersistentFromMap(map[int]int{1: 1, 2: 2, 3: 3}, func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pm := base
+			for i := 0; i < 100; i++ {
+				pm = pm.With(10+w*100+i, i).Without(i%3 + 1)
+			}
+			if pm.Len() != 100 {
+				t.Errorf("Len() = %d, want 100", pm.Len())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := base.CollectKeys(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("CollectKeys() = %v, want [1 2 3]", got)
+	}
+}
+
+func ExamplePersistentSortedMap_With() {
+	v1 := NewPersistentFromMap(map[string]int{
+		"Bob":   42,
+		"Alice": 30,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	v2 := v1.With("Charlie", 25)
+	v3 := v2.Without("Alice")
+
+	fmt.Println(v1.CollectKeys())
+	fmt.Println(v2.CollectKeys())
+	fmt.Println(v3.CollectKeys())
+	// Output:
+	// [Alice Bob]
+	// [Alice Bob Charlie]
+	// [Bob Charlie]
+}
+
+func BenchmarkPersistentSortedMap_With(b *testing.B) {
+	const n = 10_000
+	pm := NewPersistent[map[int]int](func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < n; i++ {
+		pm = pm.With(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pm.With(i%n, i)
+	}
+}
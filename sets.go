@@ -0,0 +1,127 @@
+package sortedmap
+
+// Union returns a new SortedMap with the pairs of both maps. For a key which is in both maps, the value is
+// resolve(key, a's value, b's value), or b's value if resolve is nil.
+// The maps are expected to have the same comparison function, the result uses the one and the options of a,
+// opts are applied on top of them.
+// The complexity is O(n + m), O((n + m) log(n + m)) if resolve changes the order of the pairs
+func Union[Map ~map[K]V, K comparable, V any](
+	a, b *SortedMap[Map, K, V],
+	resolve func(key K, av, bv V) V,
+	opts ...Option,
+) *SortedMap[Map, K, V] {
+	pairs := make([]KV[K, V], 0, a.Len()+b.Len())
+	merge(a, b, func(el KV[K, V], fromA bool) bool {
+		bv, inB := b.Get(el.Key)
+		switch {
+		case fromA && inB:
+			pairs = append(pairs, KV[K, V]{el.Key, resolveVal(resolve, el.Key, el.Val, bv)})
+		case fromA:
+			pairs = append(pairs, el)
+		case !a.has(el.Key):
+			pairs = append(pairs, el)
+		}
+		return true
+	})
+
+	return newLoaded[Map](a.less, pairs, a.o.with(opts), false)
+}
+
+// Intersect returns a new SortedMap with the keys which are in both maps. The value is
+// resolve(key, a's value, b's value), or b's value if resolve is nil.
+// The maps are expected to have the same comparison function, the result uses the one and the options of a.
+// The complexity is O(n), O(n log n) if resolve changes the order of the pairs
+func Intersect[Map ~map[K]V, K comparable, V any](
+	a, b *SortedMap[Map, K, V],
+	resolve func(key K, av, bv V) V,
+	opts ...Option,
+) *SortedMap[Map, K, V] {
+	var pairs []KV[K, V]
+	for k, av := range a.All() {
+		if bv, ok := b.Get(k); ok {
+			pairs = append(pairs, KV[K, V]{k, resolveVal(resolve, k, av, bv)})
+		}
+	}
+
+	return newLoaded[Map](a.less, pairs, a.o.with(opts), false)
+}
+
+// Difference returns a new SortedMap with the pairs of a which keys are not in b. It has the comparison function
+// and the options of a.
+// The complexity is O(n)
+func Difference[Map ~map[K]V, K comparable, V any](a, b *SortedMap[Map, K, V], opts ...Option) *SortedMap[Map, K, V] {
+	var pairs []KV[K, V]
+	for k, v := range a.All() {
+		if !b.has(k) {
+			pairs = append(pairs, KV[K, V]{k, v})
+		}
+	}
+
+	return newLoaded[Map](a.less, pairs, a.o.with(opts), true)
+}
+
+// SymmetricDifference returns a new SortedMap with the pairs which keys are in only one of the maps.
+// The maps are expected to have the same comparison function, the result uses the one and the options of a.
+// The complexity is O(n + m)
+func SymmetricDifference[Map ~map[K]V, K comparable, V any](
+	a, b *SortedMap[Map, K, V],
+	opts ...Option,
+) *SortedMap[Map, K, V] {
+	var pairs []KV[K, V]
+	merge(a, b, func(el KV[K, V], fromA bool) bool {
+		if fromA && !b.has(el.Key) || !fromA && !a.has(el.Key) {
+			pairs = append(pairs, el)
+		}
+		return true
+	})
+
+	return newLoaded[Map](a.less, pairs, a.o.with(opts), false)
+}
+
+// merge calls fn for the pairs of both maps in the order of a until fn returns false.
+// Of equal pairs, the ones of a go first.
+func merge[Map ~map[K]V, K comparable, V any](a, b *SortedMap[Map, K, V], fn func(el KV[K, V], fromA bool) bool) {
+	xs, ys := a.CollectAll(), b.CollectAll()
+	i, j := 0, 0
+	for i < len(xs) || j < len(ys) {
+		var ok bool
+		if i == len(xs) || j < len(ys) && a.less(ys[j], xs[i]) {
+			ok = fn(ys[j], false)
+			j++
+		} else {
+			ok = fn(xs[i], true)
+			i++
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func resolveVal[K comparable, V any](resolve func(key K, av, bv V) V, key K, av, bv V) V {
+	if resolve == nil {
+		return bv
+	}
+
+	return resolve(key, av, bv)
+}
+
+// newLoaded creates a new SortedMap with the options from the pairs, which are usually in order already.
+// The order is not checked if the pairs are presorted.
+func newLoaded[Map ~map[K]V, K comparable, V any](
+	less func(i, j KV[K, V]) bool,
+	pairs []KV[K, V],
+	o options,
+	presorted bool,
+) *SortedMap[Map, K, V] {
+	sm := newWithBackend[Map](NewBackend(o.storage, less), less, o)
+	_ = sm.loadWith(pairs, presorted, KeepLast)
+
+	return sm
+}
+
+func (sm *SortedMap[Map, K, V]) has(key K) bool {
+	_, ok := sm.m[key]
+
+	return ok
+}
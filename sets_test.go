@@ -0,0 +1,181 @@
+package sortedmap
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSets(t *testing.T) {
+	byKey := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	sum := func(_ string, av, bv int) int {
+		return av + bv
+	}
+	a := map[string]int{"a": 1, "b": 2, "c": 3}
+	b := map[string]int{"b": 20, "c": 30, "d": 40}
+
+	tests := []struct {
+		name string
+		fn   func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int]
+		want []KV[string, int]
+	}{
+		{
+			name: "Union",
+			fn: func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int] {
+				return Union(a, b, sum)
+			},
+			want: []KV[string, int]{{"a", 1}, {"b", 22}, {"c", 33}, {"d", 40}},
+		},
+		{
+			name: "Union without resolve",
+			fn: func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int] {
+				return Union(a, b, nil)
+			},
+			want: []KV[string, int]{{"a", 1}, {"b", 20}, {"c", 30}, {"d", 40}},
+		},
+		{
+			name: "Intersect",
+			fn: func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int] {
+				return Intersect(a, b, sum)
+			},
+			want: []KV[string, int]{{"b", 22}, {"c", 33}},
+		},
+		{
+			name: "Intersect without resolve",
+			fn: func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int] {
+				return Intersect(a, b, nil)
+			},
+			want: []KV[string, int]{{"b", 20}, {"c", 30}},
+		},
+		{
+			name: "Difference",
+			fn: func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int] {
+				return Difference(a, b)
+			},
+			want: []KV[string, int]{{"a", 1}},
+		},
+		{
+			name: "SymmetricDifference",
+			fn: func(a, b *SortedMap[map[string]int, string, int]) *SortedMap[map[string]int, string, int] {
+				return SymmetricDifference(a, b)
+			},
+			want: []KV[string, int]{{"a", 1}, {"d", 40}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			am, bm := NewFromMap(a, byKey), NewFromMap(b, byKey)
+			got := tt.fn(am, bm)
+			if !slices.Equal(got.CollectAll(), tt.want) {
+				t.Errorf("%s() = %v, want %v", tt.name, got.CollectAll(), tt.want)
+			}
+			if got.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", got.Len(), len(tt.want))
+			}
+			// the operands are not changed
+			if am.Len() != len(a) || bm.Len() != len(b) {
+				t.Errorf("operands changed: %v, %v", am.CollectAll(), bm.CollectAll())
+			}
+		})
+	}
+}
+
+func TestSets_byValue(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			// the shared keys have different values, so they are at different positions of the maps
+			a := NewFromMap(map[string]int{"x": 1, "y": 5, "z": 9}, byVal, WithStorage(s))
+			b := NewFromMap(map[string]int{"y": 0, "w": 4, "z": 10}, byVal, WithStorage(s))
+
+			got := Union(a, b, func(_ string, av, bv int) int {
+				return max(av, bv)
+			})
+			want := []KV[string, int]{{"x", 1}, {"w", 4}, {"y", 5}, {"z", 10}}
+			if !slices.Equal(got.CollectAll(), want) {
+				t.Errorf("Union() = %v, want %v", got.CollectAll(), want)
+			}
+
+			// the resolved values change the order, the result is still sorted
+			got = Intersect(a, b, func(_ string, av, bv int) int {
+				return -av - bv
+			})
+			want = []KV[string, int]{{"z", -19}, {"y", -5}}
+			if !slices.Equal(got.CollectAll(), want) {
+				t.Errorf("Intersect() = %v, want %v", got.CollectAll(), want)
+			}
+
+			got = SymmetricDifference(a, b)
+			// the result has the storage of a
+			if gt, at := fmt.Sprintf("%T", got.b), fmt.Sprintf("%T", a.b); gt != at {
+				t.Errorf("SymmetricDifference() storage = %s, want %s", gt, at)
+			}
+			want = []KV[string, int]{{"x", 1}, {"w", 4}}
+			if !slices.Equal(got.CollectAll(), want) {
+				t.Errorf("SymmetricDifference() = %v, want %v", got.CollectAll(), want)
+			}
+			got.Insert("v", 2)
+			checkSorted(t, got.CollectAll(), map[string]int{"x": 1, "w": 4, "v": 2}, byVal)
+		})
+	}
+}
+
+func TestSets_empty(t *testing.T) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	empty := New[map[int]int](less)
+	m := NewFromMap(map[int]int{1: 1, 2: 2}, less)
+
+	if got := Union(empty, m, nil); !slices.Equal(got.CollectAll(), m.CollectAll()) {
+		t.Errorf("Union() = %v, want %v", got.CollectAll(), m.CollectAll())
+	}
+	if got := Intersect(m, empty, nil); got.Len() != 0 {
+		t.Errorf("Intersect() = %v, want empty", got.CollectAll())
+	}
+	if got := Difference(m, empty); !slices.Equal(got.CollectAll(), m.CollectAll()) {
+		t.Errorf("Difference() = %v, want %v", got.CollectAll(), m.CollectAll())
+	}
+	if got := SymmetricDifference(m, m); got.Len() != 0 {
+		t.Errorf("SymmetricDifference() = %v, want empty", got.CollectAll())
+	}
+}
+
+func ExampleUnion() {
+	less := func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	}
+	stock := NewFromMap(map[string]int{"apples": 3, "pears": 1}, less)
+	delivery := NewFromMap(map[string]int{"pears": 5, "plums": 2}, less)
+
+	total := Union(stock, delivery, func(_ string, have, got int) int {
+		return have + got
+	})
+	for k, v := range total.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// apples 3
+	// pears 6
+	// plums 2
+}
+
+func BenchmarkUnion(b *testing.B) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	}
+	x, y := New[map[int]int](less), New[map[int]int](less)
+	for i := range 10_000 {
+		x.Insert(i*2, i)
+		y.Insert(i*3, i)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		Union(x, y, nil)
+	}
+}
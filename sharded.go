@@ -0,0 +1,188 @@
+package sortedmap
+
+import (
+	"container/heap"
+	"hash/maphash"
+	"iter"
+)
+
+// ShardedSortedMap is a sorted map which is safe for concurrent use and spreads the keys by their hash
+// across several shards. Every shard has its own lock, so writers of different keys rarely wait for each other.
+//
+// Iterators merge the shards into one ordered sequence. Every shard is read as a snapshot taken when
+// the iteration starts, but the shards are not locked together, so a change made during that moment may be
+// seen in one shard and not in another. The order of equal pairs from different shards is not defined.
+type ShardedSortedMap[Map ~map[K]V, K comparable, V any] struct {
+	shards []*ConcurrentSortedMap[Map, K, V]
+	seed   maphash.Seed
+	less   func(i, j KV[K, V]) bool
+}
+
+// NewSharded creates a new ShardedSortedMap with n shards and `less` as the comparison function
+// The complexity is O(n)
+func NewSharded[Map ~map[K]V, K comparable, V any](
+	n int,
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) *ShardedSortedMap[Map, K, V] {
+	if n < 1 {
+		panic("number of shards must be positive")
+	}
+	shards := make([]*ConcurrentSortedMap[Map, K, V], n)
+	for i := range shards {
+		shards[i] = NewConcurrent[Map, K, V](less, opts...)
+	}
+
+	return &ShardedSortedMap[Map, K, V]{
+		shards: shards,
+		seed:   maphash.MakeSeed(),
+		less:   less,
+	}
+}
+
+// NewShardedFromMap creates a new ShardedSortedMap with n shards and `less` as the comparison function
+// and populates it with the contents of `m`.
+// The complexity is O(m log m) where m = len(m).
+func NewShardedFromMap[Map ~map[K]V, K comparable, V any](
+	n int,
+	m Map,
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) *ShardedSortedMap[Map, K, V] {
+	sm := NewSharded[Map, K, V](n, less, opts...)
+	for k, v := range m {
+		sm.Insert(k, v)
+	}
+
+	return sm
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists in the map
+// The complexity is O(1)
+func (sm *ShardedSortedMap[Map, K, V]) Get(key K) (V, bool) {
+	return sm.shard(key).Get(key)
+}
+
+// Insert adds a key-value pair to the map. If the key already exists, the value is updated
+// The complexity is O(log n)
+func (sm *ShardedSortedMap[Map, K, V]) Insert(key K, val V) {
+	sm.shard(key).Insert(key, val)
+}
+
+// Delete removes the key from the map and returns the value associated with the key and a boolean indicating
+// if the key existed in the map.
+// The complexity is O(log n)
+func (sm *ShardedSortedMap[Map, K, V]) Delete(key K) (val *V, existed bool) {
+	return sm.shard(key).Delete(key)
+}
+
+// Len returns the number of the pairs in all the shards
+// The complexity is O(s) where s is the number of shards
+func (sm *ShardedSortedMap[Map, K, V]) Len() int {
+	n := 0
+	for _, s := range sm.shards {
+		n += s.Len()
+	}
+
+	return n
+}
+
+// All returns a sequence of key-value pairs of all the shards merged in the order
+// The complexity is O(n log s) where s is the number of shards
+func (sm *ShardedSortedMap[Map, K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		snaps := make([][]KV[K, V], len(sm.shards))
+		// heads holds the first not yet yielded pair of every shard
+		heads := newKvHeap(sm.less)
+		for i, s := range sm.shards {
+			snaps[i] = s.CollectAll()
+			if len(snaps[i]) > 0 {
+				heads.Insert(snaps[i][0])
+			}
+		}
+		for heads.Len() > 0 {
+			el := heap.Pop(heads).(KV[K, V])
+			if !yield(el.Key, el.Val) {
+				return
+			}
+			i := sm.shardIndex(el.Key)
+			if snaps[i] = snaps[i][1:]; len(snaps[i]) > 0 {
+				heads.Insert(snaps[i][0])
+			}
+		}
+	}
+}
+
+// Keys returns a sequence of keys of all the shards merged in the order
+// The complexity is O(n log s) where s is the number of shards
+func (sm *ShardedSortedMap[Map, K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range sm.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of values of all the shards merged in the order
+// The complexity is O(n log s) where s is the number of shards
+func (sm *ShardedSortedMap[Map, K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range sm.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns a regular map with an *unordered* content off the map
+func (sm *ShardedSortedMap[Map, K, V]) Collect() Map {
+	m := make(Map, sm.Len())
+	for _, s := range sm.shards {
+		for k, v := range s.Collect() {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+// CollectAll returns a slice of key-value pairs
+func (sm *ShardedSortedMap[Map, K, V]) CollectAll() []KV[K, V] {
+	pairs := make([]KV[K, V], 0, sm.Len())
+	for k, v := range sm.All() {
+		pairs = append(pairs, KV[K, V]{k, v})
+	}
+
+	return pairs
+}
+
+// CollectKeys returns a slice of the map’s keys
+func (sm *ShardedSortedMap[Map, K, V]) CollectKeys() []K {
+	ks := make([]K, 0, sm.Len())
+	for k := range sm.Keys() {
+		ks = append(ks, k)
+	}
+
+	return ks
+}
+
+// CollectValues returns a slice of the map's values
+func (sm *ShardedSortedMap[Map, K, V]) CollectValues() []V {
+	vals := make([]V, 0, sm.Len())
+	for val := range sm.Values() {
+		vals = append(vals, val)
+	}
+
+	return vals
+}
+
+func (sm *ShardedSortedMap[Map, K, V]) shard(key K) *ConcurrentSortedMap[Map, K, V] {
+	return sm.shards[sm.shardIndex(key)]
+}
+
+func (sm *ShardedSortedMap[Map, K, V]) shardIndex(key K) int {
+	return int(maphash.Comparable(sm.seed, key) % uint64(len(sm.shards))) //nolint:gosec // less than len(shards)
+}
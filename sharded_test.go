@@ -0,0 +1,146 @@
+package sortedmap
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestShardedSortedMap(t *testing.T) {
+	byKey := func(i, j KV[int, int]) bool { return i.Key < j.Key }
+	byVal := func(i, j KV[int, int]) bool { return i.Val < j.Val }
+	tests := []struct {
+		name   string
+		shards int
+		less   func(i, j KV[int, int]) bool
+	}{
+		{"single shard by key", 1, byKey},
+		{"many shards by key", 8, byKey},
+		{"many shards by value", 8, byVal},
+		{"more shards than keys", 1000, byVal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewSharded[map[int]int](tt.shards, tt.less)
+			want := map[int]int{}
+			for i := 0; i < 500; i++ {
+				key, val := i*31%200, i*17%100
+				if i%4 == 0 {
+					sm.Delete(key)
+					delete(want, key)
+				} else {
+					sm.Insert(key, val)
+					want[key] = val
+				}
+			}
+
+			checkSorted(t, sm.CollectAll(), want, tt.less)
+			if sm.Len() != len(want) {
+				t.Errorf("Len() = %d, want %d", sm.Len(), len(want))
+			}
+			for k, v := range want {
+				if got, ok := sm.Get(k); !ok || got != v {
+					t.Errorf("Get(%d) = %d, %v, want %d, true", k, got, ok, v)
+				}
+			}
+			if got := len(sm.Collect()); got != len(want) {
+				t.Errorf("Collect() has %d pairs, want %d", got, len(want))
+			}
+			all := sm.CollectAll()
+			keys, vals := sm.CollectKeys(), sm.CollectValues()
+			for i, el := range all {
+				if keys[i] != el.Key || vals[i] != el.Val {
+					t.Fatalf("CollectKeys()[%d], CollectValues()[%d] = %v, %v, want %v", i, i, keys[i], vals[i], el)
+				}
+			}
+			// break out of the merge early
+			for range sm.All() {
+				break
+			}
+		})
+	}
+
+	panicsWithValue(t, "number of shards must be positive", func() {
+		NewSharded[map[int]int](0, byKey)
+	})
+}
+
+func TestShardedSortedMap_race(t *testing.T) {
+	const workers = 8
+	sm := NewSharded[map[int]int](4, func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := i%100*workers + w
+				if i%3 == 0 {
+					sm.Delete(key)
+				} else {
+					sm.Insert(key, i)
+				}
+				if i%100 == 0 {
+					if keys := sm.CollectKeys(); !slices.IsSorted(keys) {
+						t.Errorf("CollectKeys() is not sorted: %v", keys)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkSorted(t, sm.CollectAll(), sm.Collect(), func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+}
+
+func ExampleNewSharded() {
+	sm := NewShardedFromMap(4, map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+		"Eve":     84,
+	}, func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	})
+
+	for k, v := range sm.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// Charlie 25
+	// Alice 30
+	// Bob 42
+	// Eve 84
+}
+
+func BenchmarkShardedSortedMap(b *testing.B) {
+	const n = 10_000
+	less := func(i, j KV[int, int]) bool { return i.Key < j.Key }
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("Insert/shards=%d", shards), func(b *testing.B) {
+			sm := NewSharded[map[int]int](shards, less)
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					sm.Insert(i*7919%n, i)
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("All/shards=%d", shards), func(b *testing.B) {
+			sm := NewSharded[map[int]int](shards, less)
+			for i := 0; i < n; i++ {
+				sm.Insert(i, i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for range sm.All() {
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package sortedmap
+
+import (
+	"iter"
+	"math/rand/v2"
+)
+
+const (
+	skipListMaxLevel = 32
+	// skipListP is the probability for a node to be promoted to the next level, as 1 in skipListP
+	skipListP = 4
+)
+
+// skipList is a probabilistic ordered list. Equal items are kept in insertion order.
+// The bottom level is doubly linked, so the list can be walked backward.
+type skipList[T any] struct {
+	head  *slNode[T]
+	level int
+	len   int
+	less  func(a, b T) bool
+	same  func(a, b T) bool
+}
+
+type slNode[T any] struct {
+	item T
+	next []*slNode[T]
+	// prev is the previous node on the bottom level, nil for the first one
+	prev *slNode[T]
+}
+
+func newSkipList[T any](less, same func(a, b T) bool) *skipList[T] {
+	return &skipList[T]{
+		head:  &slNode[T]{next: make([]*slNode[T], skipListMaxLevel)},
+		level: 1,
+		less:  less,
+		same:  same,
+	}
+}
+
+// Len returns the number of items in the list.
+func (s *skipList[T]) Len() int {
+	return s.len
+}
+
+// Insert adds the item after all the items which are not greater than it.
+// The complexity is O(log n) on average
+func (s *skipList[T]) Insert(item T) {
+	var update [skipListMaxLevel]*slNode[T]
+	x := s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for x.next[l] != nil && !s.less(item, x.next[l].item) {
+			x = x.next[l]
+		}
+		update[l] = x
+	}
+
+	level := randomLevel()
+	for ; s.level < level; s.level++ {
+		update[s.level] = s.head
+	}
+	n := &slNode[T]{item: item, next: make([]*slNode[T], level)}
+	for l := 0; l < level; l++ {
+		n.next[l] = update[l].next[l]
+		update[l].next[l] = n
+	}
+	if update[0] != s.head {
+		n.prev = update[0]
+	}
+	if n.next[0] != nil {
+		n.next[0].prev = n
+	}
+	s.len++
+}
+
+// load replaces the content of the list with the items, which have to be in ascending order.
+// The complexity is O(n) on average
+func (s *skipList[T]) load(items []T) {
+	clear(s.head.next)
+	s.level, s.len = 1, len(items)
+	// tail holds the last node on every level
+	var tail [skipListMaxLevel]*slNode[T]
+	for l := range tail {
+		tail[l] = s.head
+	}
+	for _, item := range items {
+		level := randomLevel()
+		s.level = max(s.level, level)
+		n := &slNode[T]{item: item, next: make([]*slNode[T], level)}
+		if tail[0] != s.head {
+			n.prev = tail[0]
+		}
+		for l := 0; l < level; l++ {
+			tail[l].next[l] = n
+			tail[l] = n
+		}
+	}
+}
+
+// Delete removes the item which is the same as the given one and reports whether it was found.
+// The complexity is O(log n) on average plus the number of items equal to the given one.
+func (s *skipList[T]) Delete(item T) bool {
+	var update [skipListMaxLevel]*slNode[T]
+	s.lowerBound(item, &update)
+
+	n := update[0].next[0]
+	for n != nil && !s.less(item, n.item) && !s.same(item, n.item) {
+		n = n.next[0]
+	}
+	if n == nil || s.less(item, n.item) {
+		return false
+	}
+
+	for l := range n.next {
+		// on every level the node follows the last node which is less than the item
+		p := update[l]
+		for p.next[l] != n {
+			p = p.next[l]
+		}
+		p.next[l] = n.next[l]
+	}
+	if n.next[0] != nil {
+		n.next[0].prev = n.prev
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.len--
+
+	return true
+}
+
+// All returns a sequence of the items in ascending order.
+// The complexity is O(n) for the whole traversal.
+func (s *skipList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		walkList(s.head.next[0], yield)
+	}
+}
+
+// Backward returns a sequence of the items in descending order.
+// The complexity is O(n) for the whole traversal.
+func (s *skipList[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		x := s.head
+		for l := s.level - 1; l >= 0; l-- {
+			for x.next[l] != nil {
+				x = x.next[l]
+			}
+		}
+		if x != s.head {
+			walkListBackward(x, yield)
+		}
+	}
+}
+
+// Seek returns a sequence of the items in ascending order, starting from the first one which is not less than probe.
+// The complexity is O(log n) on average to find the first item.
+func (s *skipList[T]) Seek(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var update [skipListMaxLevel]*slNode[T]
+		s.lowerBound(probe, &update)
+		walkList(update[0].next[0], yield)
+	}
+}
+
+// SeekBackward returns a sequence of the items in descending order, starting from the last one which is not
+// greater than probe.
+// The complexity is O(log n) on average to find the first item.
+func (s *skipList[T]) SeekBackward(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		x := s.head
+		for l := s.level - 1; l >= 0; l-- {
+			for x.next[l] != nil && !s.less(probe, x.next[l].item) {
+				x = x.next[l]
+			}
+		}
+		if x != s.head {
+			walkListBackward(x, yield)
+		}
+	}
+}
+
+// AllFrom returns a sequence of the items in ascending order, starting from the i-th one.
+// The complexity is O(n)
+func (s *skipList[T]) AllFrom(i int) iter.Seq[T] {
+	return skipItems(s.All(), i)
+}
+
+// Rank returns the position of the item which is the same as the given one, or -1 if there is no such item.
+// The complexity is O(n)
+func (s *skipList[T]) Rank(item T) int {
+	return rankOf(s.All(), item, s.same)
+}
+
+// At returns the i-th item and false if there is no such item.
+// The complexity is O(n)
+func (s *skipList[T]) At(i int) (T, bool) {
+	return itemAt(s.All(), i)
+}
+
+// Min returns the first item and false if the list is empty.
+// The complexity is O(1)
+func (s *skipList[T]) Min() (T, bool) {
+	n := s.head.next[0]
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.item, true
+}
+
+// Max returns the last item and false if the list is empty.
+// The complexity is O(log n) on average
+func (s *skipList[T]) Max() (T, bool) {
+	x := s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for x.next[l] != nil {
+			x = x.next[l]
+		}
+	}
+	if x == s.head {
+		var zero T
+		return zero, false
+	}
+
+	return x.item, true
+}
+
+// lowerBound fills update with the last nodes on every level which are less than the item.
+func (s *skipList[T]) lowerBound(item T, update *[skipListMaxLevel]*slNode[T]) {
+	x := s.head
+	for l := s.level - 1; l >= 0; l-- {
+		for x.next[l] != nil && s.less(x.next[l].item, item) {
+			x = x.next[l]
+		}
+		update[l] = x
+	}
+}
+
+func walkList[T any](n *slNode[T], yield func(T) bool) {
+	for ; n != nil; n = n.next[0] {
+		if !yield(n.item) {
+			return
+		}
+	}
+}
+
+func walkListBackward[T any](n *slNode[T], yield func(T) bool) {
+	for ; n != nil; n = n.prev {
+		if !yield(n.item) {
+			return
+		}
+	}
+}
+
+func randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && rand.IntN(skipListP) == 0 { //nolint:gosec // no need for a secure random here
+		level++
+	}
+
+	return level
+}
@@ -0,0 +1,222 @@
+package sortedmap
+
+import (
+	"iter"
+	"slices"
+)
+
+// sortedSlice is a slice which is kept in ascending order. Equal items are kept in insertion order.
+type sortedSlice[T any] struct {
+	xs   []T
+	less func(a, b T) bool
+	same func(a, b T) bool
+}
+
+func newSortedSlice[T any](less, same func(a, b T) bool) *sortedSlice[T] {
+	return &sortedSlice[T]{
+		less: less,
+		same: same,
+	}
+}
+
+// Len returns the number of items in the slice.
+func (s *sortedSlice[T]) Len() int {
+	return len(s.xs)
+}
+
+// Insert adds the item after all the items which are not greater than it.
+// The complexity is O(n)
+func (s *sortedSlice[T]) Insert(item T) {
+	i := s.upperBound(item)
+	s.xs = slices.Insert(s.xs, i, item)
+}
+
+// load replaces the content of the slice with the items, which have to be in ascending order.
+// The complexity is O(n)
+func (s *sortedSlice[T]) load(items []T) {
+	s.xs = slices.Clone(items)
+}
+
+// insertMany adds the items like a series of Insert calls, but shifts the slice only once. The items are sorted
+// in place and merged with the slice.
+// The complexity is O(n + k log k)
+func (s *sortedSlice[T]) insertMany(items []T) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		switch {
+		case s.less(a, b):
+			return -1
+		case s.less(b, a):
+			return 1
+		}
+		return 0
+	})
+
+	xs := make([]T, 0, len(s.xs)+len(items))
+	i := 0
+	for _, x := range s.xs {
+		// the new items go after the ones which are equal to them
+		for ; i < len(items) && s.less(items[i], x); i++ {
+			xs = append(xs, items[i])
+		}
+		xs = append(xs, x)
+	}
+	s.xs = append(xs, items[i:]...)
+}
+
+// deleteMany removes the items which are the same as the given ones, shifting the slice only once.
+// The complexity is O(n + k log n)
+func (s *sortedSlice[T]) deleteMany(items []T) {
+	drop := make([]bool, len(s.xs))
+	for _, item := range items {
+		if i := s.Rank(item); i >= 0 {
+			drop[i] = true
+		}
+	}
+	n := 0
+	for i, x := range s.xs {
+		if !drop[i] {
+			s.xs[n] = x
+			n++
+		}
+	}
+	clear(s.xs[n:])
+	s.xs = s.xs[:n]
+}
+
+// Delete removes the item which is the same as the given one and reports whether it was found.
+// The complexity is O(n)
+func (s *sortedSlice[T]) Delete(item T) bool {
+	i := s.Rank(item)
+	if i < 0 {
+		return false
+	}
+	s.xs = slices.Delete(s.xs, i, i+1)
+
+	return true
+}
+
+// All returns a sequence of the items in ascending order.
+// The complexity is O(n) for the whole traversal.
+func (s *sortedSlice[T]) All() iter.Seq[T] {
+	return s.from(0)
+}
+
+// Backward returns a sequence of the items in descending order.
+// The complexity is O(n) for the whole traversal.
+func (s *sortedSlice[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(s.xs) - 1; i >= 0; i-- {
+			if !yield(s.xs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Seek returns a sequence of the items in ascending order, starting from the first one which is not less than probe.
+// The complexity is O(log n) to find the first item.
+func (s *sortedSlice[T]) Seek(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.from(s.lowerBound(probe))(yield)
+	}
+}
+
+// SeekBackward returns a sequence of the items in descending order, starting from the last one which is not
+// greater than probe.
+// The complexity is O(log n) to find the first item.
+func (s *sortedSlice[T]) SeekBackward(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := s.upperBound(probe) - 1; i >= 0; i-- {
+			if !yield(s.xs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// AllFrom returns a sequence of the items in ascending order, starting from the i-th one.
+// The complexity is O(1) to find the first item.
+func (s *sortedSlice[T]) AllFrom(i int) iter.Seq[T] {
+	return s.from(max(i, 0))
+}
+
+// Rank returns the position of the item which is the same as the given one, or -1 if there is no such item.
+// The complexity is O(log n) plus the number of items equal to the given one.
+func (s *sortedSlice[T]) Rank(item T) int {
+	for i := s.lowerBound(item); i < len(s.xs) && !s.less(item, s.xs[i]); i++ {
+		if s.same(item, s.xs[i]) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// At returns the i-th item and false if there is no such item.
+// The complexity is O(1)
+func (s *sortedSlice[T]) At(i int) (T, bool) {
+	if i < 0 || i >= len(s.xs) {
+		var zero T
+		return zero, false
+	}
+
+	return s.xs[i], true
+}
+
+// Min returns the first item and false if the slice is empty.
+// The complexity is O(1)
+func (s *sortedSlice[T]) Min() (T, bool) {
+	if len(s.xs) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return s.xs[0], true
+}
+
+// Max returns the last item and false if the slice is empty.
+// The complexity is O(1)
+func (s *sortedSlice[T]) Max() (T, bool) {
+	if len(s.xs) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return s.xs[len(s.xs)-1], true
+}
+
+func (s *sortedSlice[T]) from(i int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for j := i; j < len(s.xs); j++ {
+			if !yield(s.xs[j]) {
+				return
+			}
+		}
+	}
+}
+
+// lowerBound returns the index of the first item which is not less than the given one.
+func (s *sortedSlice[T]) lowerBound(item T) int {
+	i, _ := slices.BinarySearchFunc(s.xs, item, func(x, item T) int {
+		if s.less(x, item) {
+			return -1
+		}
+
+		return 1
+	})
+
+	return i
+}
+
+// upperBound returns the index of the first item which is greater than the given one.
+func (s *sortedSlice[T]) upperBound(item T) int {
+	i, _ := slices.BinarySearchFunc(s.xs, item, func(x, item T) int {
+		if s.less(item, x) {
+			return 1
+		}
+
+		return -1
+	})
+
+	return i
+}
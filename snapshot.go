@@ -0,0 +1,190 @@
+package sortedmap
+
+import (
+	"iter"
+	"slices"
+	"sync"
+)
+
+// Snapshot is a read-only view of a SortedMap at the moment it was taken. Later changes of the map are not
+// visible in it. A snapshot never changes, so it's safe for concurrent use by multiple goroutines, and reading it
+// doesn't need any coordination with the writers of the map.
+type Snapshot[Map ~map[K]V, K comparable, V any] struct {
+	sm *SortedMap[Map, K, V]
+	// m is the key index, it's only needed by Get and Rank, so it's built on the first call of them
+	m    Map
+	once sync.Once
+}
+
+// Snapshot returns a read-only view of the map. With the Tree storage it shares all the nodes with the map:
+// after that both of them copy a node before changing it, so only the changed paths are ever copied.
+// Other storages are copied into a new tree.
+// The complexity is O(1), O(n) for storages other than Tree, O(n log n) for Heap
+func (sm *SortedMap[Map, K, V]) Snapshot() *Snapshot[Map, K, V] {
+	var b Backend[K, V]
+	if t, ok := sm.b.(*tree[KV[K, V]]); ok {
+		b = t.snapshot()
+	} else {
+		t := newTree(sm.less, sameKey[K, V])
+		t.load(slices.Collect(sm.b.All()))
+		b = t
+	}
+
+	return &Snapshot[Map, K, V]{
+		sm: &SortedMap[Map, K, V]{b: b, less: sm.less, o: sm.o},
+	}
+}
+
+// Snapshot returns a read-only view of the map, see SortedMap.Snapshot
+// The complexity is O(1), O(n) for storages other than Tree, O(n log n) for Heap
+func (cm *ConcurrentSortedMap[Map, K, V]) Snapshot() *Snapshot[Map, K, V] {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.sm.Snapshot()
+}
+
+// index returns the key index, building it on the first call.
+func (s *Snapshot[Map, K, V]) index() Map {
+	s.once.Do(func() {
+		s.m = make(Map, s.sm.b.Len())
+		for el := range s.sm.b.All() {
+			s.m[el.Key] = el.Val
+		}
+	})
+
+	return s.m
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists in the snapshot
+// The complexity is O(1), the first call builds the key index in O(n)
+func (s *Snapshot[Map, K, V]) Get(key K) (V, bool) {
+	val, exists := s.index()[key]
+
+	return val, exists
+}
+
+// Len returns the number of the pairs in the snapshot
+func (s *Snapshot[Map, K, V]) Len() int {
+	return s.sm.b.Len()
+}
+
+// All returns a sequence of key-value pairs
+func (s *Snapshot[Map, K, V]) All() iter.Seq2[K, V] {
+	return s.sm.All()
+}
+
+// AllFrom returns a sequence of key-value pairs starting from the i-th one in the order
+// The complexity is O(log n) to find the first pair
+func (s *Snapshot[Map, K, V]) AllFrom(i int) iter.Seq2[K, V] {
+	return s.sm.AllFrom(i)
+}
+
+// Keys returns a sequence of keys
+func (s *Snapshot[Map, K, V]) Keys() iter.Seq[K] {
+	return s.sm.Keys()
+}
+
+// Values returns a sequence of values
+func (s *Snapshot[Map, K, V]) Values() iter.Seq[V] {
+	return s.sm.Values()
+}
+
+// Backward returns a sequence of key-value pairs in the reverse order
+func (s *Snapshot[Map, K, V]) Backward() iter.Seq2[K, V] {
+	return s.sm.Backward()
+}
+
+// KeysBackward returns a sequence of keys in the reverse order
+func (s *Snapshot[Map, K, V]) KeysBackward() iter.Seq[K] {
+	return s.sm.KeysBackward()
+}
+
+// ValuesBackward returns a sequence of values in the reverse order
+func (s *Snapshot[Map, K, V]) ValuesBackward() iter.Seq[V] {
+	return s.sm.ValuesBackward()
+}
+
+// Range returns a sequence of key-value pairs which are between `from` and `to` in the order,
+// see SortedMap.Range
+// The complexity is O(log n) to find the first pair
+func (s *Snapshot[Map, K, V]) Range(from, to KV[K, V], opts ...RangeOption) iter.Seq2[K, V] {
+	return s.sm.Range(from, to, opts...)
+}
+
+// Rank returns the position of the key in the order and a boolean indicating if the key exists in the snapshot
+// The complexity is O(log n), the first call builds the key index in O(n)
+func (s *Snapshot[Map, K, V]) Rank(key K) (int, bool) {
+	val, exists := s.index()[key]
+	if !exists {
+		return -1, false
+	}
+
+	return s.sm.b.Rank(KV[K, V]{key, val}), true
+}
+
+// At returns the i-th key-value pair in the order and a boolean indicating if there is such a pair
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) At(i int) (K, V, bool) {
+	return s.sm.At(i)
+}
+
+// Floor returns the greatest key-value pair which is less than or equal to the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) Floor(probe KV[K, V]) (K, V, bool) {
+	return s.sm.Floor(probe)
+}
+
+// Ceiling returns the least key-value pair which is greater than or equal to the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) Ceiling(probe KV[K, V]) (K, V, bool) {
+	return s.sm.Ceiling(probe)
+}
+
+// Lower returns the greatest key-value pair which is strictly less than the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) Lower(probe KV[K, V]) (K, V, bool) {
+	return s.sm.Lower(probe)
+}
+
+// Higher returns the least key-value pair which is strictly greater than the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) Higher(probe KV[K, V]) (K, V, bool) {
+	return s.sm.Higher(probe)
+}
+
+// Min returns the first key-value pair in the order and a boolean indicating if the snapshot is not empty
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) Min() (K, V, bool) {
+	return s.sm.Min()
+}
+
+// Max returns the last key-value pair in the order and a boolean indicating if the snapshot is not empty
+// The complexity is O(log n)
+func (s *Snapshot[Map, K, V]) Max() (K, V, bool) {
+	return s.sm.Max()
+}
+
+// Collect returns a regular map with an *unordered* content off the snapshot
+func (s *Snapshot[Map, K, V]) Collect() Map {
+	return s.sm.Collect()
+}
+
+// CollectAll returns a slice of key-value pairs
+func (s *Snapshot[Map, K, V]) CollectAll() []KV[K, V] {
+	return s.sm.CollectAll()
+}
+
+// CollectKeys returns a slice of the snapshot’s keys
+func (s *Snapshot[Map, K, V]) CollectKeys() []K {
+	return s.sm.CollectKeys()
+}
+
+// CollectValues returns a slice of the snapshot's values
+func (s *Snapshot[Map, K, V]) CollectValues() []V {
+	return s.sm.CollectValues()
+}
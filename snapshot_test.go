@@ -0,0 +1,148 @@
+package sortedmap
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+)
+
+func TestSortedMap_Snapshot(t *testing.T) {
+	less := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, less, WithStorage(s))
+			snap := sm.Snapshot()
+			want := sm.Collect()
+
+			sm.Insert("Eve", 20)
+			sm.Insert("Bob", 10)
+			sm.Delete("Alice")
+
+			checkSorted(t, snap.CollectAll(), want, less)
+			if tr, ok := snap.sm.b.(*tree[KV[string, int]]); ok {
+				checkTree(t, tr, tr.root)
+			}
+			if got := snap.Len(); got != 3 {
+				t.Errorf("Len() = %d, want 3", got)
+			}
+			tests := []struct {
+				key     string
+				wantVal int
+				wantOk  bool
+				wantPos int
+			}{
+				{"Alice", 30, true, 1},
+				{"Bob", 42, true, 2},
+				{"Eve", 0, false, -1},
+			}
+			for _, tt := range tests {
+				if v, ok := snap.Get(tt.key); v != tt.wantVal || ok != tt.wantOk {
+					t.Errorf("Get(%v) = %v, %v, want %v, %v", tt.key, v, ok, tt.wantVal, tt.wantOk)
+				}
+				if pos, ok := snap.Rank(tt.key); pos != tt.wantPos || ok != tt.wantOk {
+					t.Errorf("Rank(%v) = %v, %v, want %v, %v", tt.key, pos, ok, tt.wantPos, tt.wantOk)
+				}
+			}
+			if k, _, _ := snap.Min(); k != "Charlie" {
+				t.Errorf("Min() = %v, want Charlie", k)
+			}
+			if k, _, _ := snap.Max(); k != "Bob" {
+				t.Errorf("Max() = %v, want Bob", k)
+			}
+			if k, _, _ := snap.Floor(KV[string, int]{Val: 35}); k != "Alice" {
+				t.Errorf("Floor(35) = %v, want Alice", k)
+			}
+			if got := snap.CollectKeys(); fmt.Sprint(got) != "[Charlie Alice Bob]" {
+				t.Errorf("CollectKeys() = %v, want [Charlie Alice Bob]", got)
+			}
+			if got := snap.CollectValues(); fmt.Sprint(got) != "[25 30 42]" {
+				t.Errorf("CollectValues() = %v, want [25 30 42]", got)
+			}
+			if got := snap.Collect(); !maps.Equal(got, want) {
+				t.Errorf("Collect() = %v, want %v", got, want)
+			}
+
+			checkSorted(t, sm.CollectAll(), map[string]int{"Bob": 10, "Charlie": 25, "Eve": 20}, less)
+		})
+	}
+}
+
+func TestSortedMap_Snapshot_race(t *testing.T) {
+	cm := NewConcurrent[map[int]int](func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < 100; i++ {
+		cm.Insert(i, i)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			cm.Delete(i % 100)
+			cm.Insert(i%100, i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			snap := cm.Snapshot()
+			// the writer deletes a key and inserts it back, so a snapshot can miss one key at most
+			if n := snap.Len(); n != 99 && n != 100 {
+				t.Errorf("Len() = %d, want 99 or 100", n)
+			}
+			got := map[int]int{}
+			for k, v := range snap.All() {
+				got[k] = v
+			}
+			checkSorted(t, snap.CollectAll(), got, func(i, j KV[int, int]) bool { return i.Key < j.Key })
+			for k, v := range got {
+				if sv, ok := snap.Get(k); !ok || sv != v {
+					t.Errorf("Get(%d) = %d, %v, want %d, true", k, sv, ok, v)
+				}
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func ExampleSortedMap_Snapshot() {
+	sm := NewFromMap(map[string]int{
+		"Bob":   42,
+		"Alice": 30,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	snap := sm.Snapshot()
+	sm.Insert("Charlie", 25)
+	sm.Delete("Bob")
+
+	fmt.Println(snap.CollectKeys())
+	fmt.Println(sm.CollectKeys())
+	// Output:
+	// [Alice Bob]
+	// [Alice Charlie]
+}
+
+func BenchmarkSortedMap_Snapshot(b *testing.B) {
+	const n = 10_000
+	sm := New[map[int]int](func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < n; i++ {
+		sm.Insert(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sm.Snapshot()
+		sm.Insert(i%n, i)
+	}
+}
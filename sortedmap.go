@@ -1,37 +1,82 @@
 package sortedmap
 
 import (
-	"container/heap"
 	"iter"
 )
 
+// KV is a key-value pair.
+type KV[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
 // SortedMap is a map-like struct that keeps sorted by key or value.
-// It uses a heap to maintain the order.
+// It uses a balanced search tree to maintain the order, other storages can be chosen with WithStorage.
 type SortedMap[Map ~map[K]V, K comparable, V any] struct {
-	m Map
-	h *kvHeap[K, V]
+	m    Map
+	b    Backend[K, V]
+	less func(i, j KV[K, V]) bool
+	// o are the options the map was created with, maps derived from it get them too
+	o options
 }
 
 // New creates a new SortedMap with `less` as the comparison function
 // The complexity is O(1)
-func New[Map ~map[K]V, K comparable, V any](less func(i, j KV[K, V]) bool) *SortedMap[Map, K, V] {
+func New[Map ~map[K]V, K comparable, V any](less func(i, j KV[K, V]) bool, opts ...Option) *SortedMap[Map, K, V] {
 	if less == nil {
 		panic("less function is required")
 	}
+	o := newOptions(opts)
+
+	return newWithBackend[Map](NewBackend(o.storage, less), less, o)
+}
 
+// NewWithBackend creates a new SortedMap with `less` as the comparison function which keeps the order in b,
+// a storage of your own. b has to be empty and order the pairs with the same comparison function.
+// WithStorage has no effect on the map itself, but on the maps derived from it, e.g. by Filter.
+// The complexity is O(1)
+func NewWithBackend[Map ~map[K]V, K comparable, V any](
+	b Backend[K, V],
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) *SortedMap[Map, K, V] {
+	if less == nil {
+		panic("less function is required")
+	}
+	if b == nil || b.Len() != 0 {
+		panic("backend must be empty")
+	}
+
+	return newWithBackend[Map](b, less, newOptions(opts))
+}
+
+func newWithBackend[Map ~map[K]V, K comparable, V any](
+	b Backend[K, V],
+	less func(i, j KV[K, V]) bool,
+	o options,
+) *SortedMap[Map, K, V] {
 	return &SortedMap[Map, K, V]{
-		m: make(Map),
-		h: newKvHeap(less),
+		m:    make(Map),
+		b:    b,
+		less: less,
+		o:    o,
 	}
 }
 
 // NewFromMap creates a new SortedMap with `less` as the comparison function and populates it with the contents of `m`.
+// The pairs are sorted and the map is built at once instead of inserting them one by one.
 // The complexity is O(n log n) where n = len(m).
-func NewFromMap[Map ~map[K]V, K comparable, V any](m Map, less func(i, j KV[K, V]) bool) *SortedMap[Map, K, V] {
-	sm := New[Map, K, V](less)
+func NewFromMap[Map ~map[K]V, K comparable, V any](
+	m Map,
+	less func(i, j KV[K, V]) bool,
+	opts ...Option,
+) *SortedMap[Map, K, V] {
+	sm := New[Map, K, V](less, opts...)
+	pairs := make([]KV[K, V], 0, len(m))
 	for k, v := range m {
-		sm.Insert(k, v)
+		pairs = append(pairs, KV[K, V]{k, v})
 	}
+	sm.load(pairs)
 
 	return sm
 }
@@ -46,30 +91,34 @@ func (sm *SortedMap[Map, K, V]) Get(key K) (V, bool) {
 
 // Delete removes the key from the map and returns the value associated with the key and a boolean indicating
 // if the key existed in the map.
-// The complexity is O(n) where n = len(sm.h.xs)
+// The complexity is O(log n)
 func (sm *SortedMap[Map, K, V]) Delete(key K) (val *V, existed bool) {
-	delete(sm.m, key)
-	// TODO: in order to remove the element from the heap, we need to full scan it with O(n).
-	// 	probably we can use a map to store the index of the element in the heap, but the problem
-	//  is that element indexes will constantly change as we remove or add elements.
-	for i, el := range sm.h.xs {
-		if el.Key == key {
-			val = &el.Val
-			heap.Remove(sm.h, i)
-
-			return val, true
-		}
+	v, ok := sm.m[key]
+	if !ok {
+		return (*V)(nil), false
 	}
+	delete(sm.m, key)
+	sm.b.Delete(KV[K, V]{key, v})
 
-	return (*V)(nil), false
+	return &v, true
 }
 
 // All returns a sequence of key-value pairs
 func (sm *SortedMap[Map, K, V]) All() iter.Seq2[K, V] {
 	return func(yield func(K, V) bool) {
-		tempHeap := *sm.h
-		for tempHeap.Len() > 0 {
-			el := heap.Pop(&tempHeap).(KV[K, V])
+		for el := range sm.b.All() {
+			if !yield(el.Key, el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// AllFrom returns a sequence of key-value pairs starting from the i-th one in the order
+// The complexity is O(log n) to find the first pair, see Backend for other storages
+func (sm *SortedMap[Map, K, V]) AllFrom(i int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for el := range sm.b.AllFrom(i) {
 			if !yield(el.Key, el.Val) {
 				return
 			}
@@ -80,9 +129,7 @@ func (sm *SortedMap[Map, K, V]) All() iter.Seq2[K, V] {
 // Keys returns a sequence of keys
 func (sm *SortedMap[Map, K, V]) Keys() iter.Seq[K] {
 	return func(yield func(K) bool) {
-		tempHeap := *sm.h
-		for tempHeap.Len() > 0 {
-			el := heap.Pop(&tempHeap).(KV[K, V])
+		for el := range sm.b.All() {
 			if !yield(el.Key) {
 				return
 			}
@@ -93,9 +140,7 @@ func (sm *SortedMap[Map, K, V]) Keys() iter.Seq[K] {
 // Values returns a sequence of values
 func (sm *SortedMap[Map, K, V]) Values() iter.Seq[V] {
 	return func(yield func(V) bool) {
-		tempHeap := *sm.h
-		for tempHeap.Len() > 0 {
-			el := heap.Pop(&tempHeap).(KV[K, V])
+		for el := range sm.b.All() {
 			if !yield(el.Val) {
 				return
 			}
@@ -103,13 +148,180 @@ func (sm *SortedMap[Map, K, V]) Values() iter.Seq[V] {
 	}
 }
 
+// Backward returns a sequence of key-value pairs in reverse order
+func (sm *SortedMap[Map, K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for el := range sm.b.Backward() {
+			if !yield(el.Key, el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// KeysBackward returns a sequence of keys in reverse order
+func (sm *SortedMap[Map, K, V]) KeysBackward() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for el := range sm.b.Backward() {
+			if !yield(el.Key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesBackward returns a sequence of values in reverse order
+func (sm *SortedMap[Map, K, V]) ValuesBackward() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for el := range sm.b.Backward() {
+			if !yield(el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns a sequence of key-value pairs which are between `from` and `to` in the order, both bounds
+// are included unless ExcludeFrom or ExcludeTo options are given. The bounds are compared with the `less` function
+// of the map, so they don't have to be in the map.
+// The complexity is O(log n) to find the first pair, O(n log n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Range(from, to KV[K, V], opts ...RangeOption) iter.Seq2[K, V] {
+	var o rangeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	less := sm.less
+	return func(yield func(K, V) bool) {
+		for el := range sm.b.Seek(from) {
+			if o.excludeFrom && !less(from, el) {
+				continue
+			}
+			if less(to, el) || o.excludeTo && !less(el, to) {
+				return
+			}
+			if !yield(el.Key, el.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Rank returns the position of the key in the order and a boolean indicating if the key exists in the map
+// The complexity is O(log n), see Backend for other storages
+func (sm *SortedMap[Map, K, V]) Rank(key K) (int, bool) {
+	val, exists := sm.m[key]
+	if !exists {
+		return -1, false
+	}
+
+	return sm.b.Rank(KV[K, V]{key, val}), true
+}
+
+// At returns the i-th key-value pair in the order and a boolean indicating if there is such a pair
+// The complexity is O(log n), see Backend for other storages
+func (sm *SortedMap[Map, K, V]) At(i int) (K, V, bool) {
+	kv, ok := sm.b.At(i)
+
+	return kv.Key, kv.Val, ok
+}
+
+// Floor returns the greatest key-value pair which is less than or equal to the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n), O(n log n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Floor(probe KV[K, V]) (K, V, bool) {
+	for el := range sm.b.SeekBackward(probe) {
+		return el.Key, el.Val, true
+	}
+
+	return *new(K), *new(V), false
+}
+
+// Ceiling returns the least key-value pair which is greater than or equal to the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n), O(n log n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Ceiling(probe KV[K, V]) (K, V, bool) {
+	for el := range sm.b.Seek(probe) {
+		return el.Key, el.Val, true
+	}
+
+	return *new(K), *new(V), false
+}
+
+// Lower returns the greatest key-value pair which is strictly less than the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n), O(n log n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Lower(probe KV[K, V]) (K, V, bool) {
+	for el := range sm.b.SeekBackward(probe) {
+		if sm.less(el, probe) {
+			return el.Key, el.Val, true
+		}
+	}
+
+	return *new(K), *new(V), false
+}
+
+// Higher returns the least key-value pair which is strictly greater than the probe, and a boolean
+// indicating if there is such a pair
+// The complexity is O(log n), O(n log n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Higher(probe KV[K, V]) (K, V, bool) {
+	for el := range sm.b.Seek(probe) {
+		if sm.less(probe, el) {
+			return el.Key, el.Val, true
+		}
+	}
+
+	return *new(K), *new(V), false
+}
+
 // Insert adds a key-value pair to the map. If the key already exists, the value is updated
+// The complexity is O(log n)
 func (sm *SortedMap[Map, K, V]) Insert(key K, val V) {
-	if _, exists := sm.m[key]; exists {
-		sm.Delete(key)
+	if old, exists := sm.m[key]; exists {
+		sm.b.Delete(KV[K, V]{key, old})
 	}
 	sm.m[key] = val
-	heap.Push(sm.h, KV[K, V]{key, val})
+	sm.b.Insert(KV[K, V]{key, val})
+}
+
+// Min returns the first key-value pair in the order and a boolean indicating if the map is not empty
+// The complexity is O(log n), O(1) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Min() (K, V, bool) {
+	kv, ok := sm.b.Min()
+
+	return kv.Key, kv.Val, ok
+}
+
+// Max returns the last key-value pair in the order and a boolean indicating if the map is not empty
+// The complexity is O(log n), O(n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) Max() (K, V, bool) {
+	kv, ok := sm.b.Max()
+
+	return kv.Key, kv.Val, ok
+}
+
+// PopMin removes the first key-value pair in the order from the map and returns it with a boolean indicating
+// if the map was not empty
+// The complexity is O(log n)
+func (sm *SortedMap[Map, K, V]) PopMin() (K, V, bool) {
+	kv, ok := sm.b.Min()
+	if ok {
+		sm.Delete(kv.Key)
+	}
+
+	return kv.Key, kv.Val, ok
+}
+
+// PopMax removes the last key-value pair in the order from the map and returns it with a boolean indicating
+// if the map was not empty
+// The complexity is O(log n), O(n) for the Heap storage
+func (sm *SortedMap[Map, K, V]) PopMax() (K, V, bool) {
+	kv, ok := sm.b.Max()
+	if ok {
+		sm.Delete(kv.Key)
+	}
+
+	return kv.Key, kv.Val, ok
 }
 
 // Collect returns a regular map with an *unordered* content off the SortedMap
@@ -124,7 +336,7 @@ func (sm *SortedMap[Map, K, V]) Collect() Map {
 
 // CollectAll returns a slice of key-value pairs
 func (sm *SortedMap[Map, K, V]) CollectAll() []KV[K, V] {
-	pairs := make([]KV[K, V], 0, sm.Len())
+	pairs := make([]KV[K, V], 0, sm.b.Len())
 	for k, v := range sm.All() {
 		pairs = append(pairs, KV[K, V]{k, v})
 	}
@@ -134,7 +346,7 @@ func (sm *SortedMap[Map, K, V]) CollectAll() []KV[K, V] {
 
 // CollectKeys returns a slice of the map’s keys
 func (sm *SortedMap[Map, K, V]) CollectKeys() []K {
-	ks := make([]K, 0, sm.Len())
+	ks := make([]K, 0, sm.b.Len())
 	for k := range sm.Keys() {
 		ks = append(ks, k)
 	}
@@ -144,7 +356,7 @@ func (sm *SortedMap[Map, K, V]) CollectKeys() []K {
 
 // CollectValues returns a slice of the map's values
 func (sm *SortedMap[Map, K, V]) CollectValues() []V {
-	vals := make([]V, 0, sm.Len())
+	vals := make([]V, 0, sm.b.Len())
 	for val := range sm.Values() {
 		vals = append(vals, val)
 	}
@@ -388,6 +388,52 @@ func TestSortedMap_All(t *testing.T) {
 	}
 }
 
+func TestSortedMap_All_interleaved(t *testing.T) {
+	less := func(i, j KV[int, int]) bool {
+		return i.Val < j.Val
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := New[map[int]int](less, WithStorage(s))
+			want := map[int]int{}
+			for i := 0; i < 200; i++ {
+				key, val := (i*7)%50, (i*31)%101
+				if i%4 == 0 {
+					sm.Delete(key)
+					delete(want, key)
+				} else {
+					sm.Insert(key, val)
+					want[key] = val
+				}
+
+				// full and partial iterations must not change the map
+				checkSorted(t, sm.CollectAll(), want, less)
+				checkSorted(t, sm.CollectAll(), want, less)
+				for range sm.All() {
+					break
+				}
+				if got := slices.Collect(sm.Keys()); len(got) != len(want) {
+					t.Fatalf("Keys() has %d keys, want %d", len(got), len(want))
+				}
+				if got := slices.Collect(sm.Values()); len(got) != len(want) {
+					t.Fatalf("Values() has %d values, want %d", len(got), len(want))
+				}
+			}
+
+			// changing the map during the iteration must leave it consistent
+			for k := range sm.Keys() {
+				sm.Delete(k)
+				delete(want, k)
+				if k < 1000 && len(want)%3 == 0 {
+					sm.Insert(k+1000, len(want))
+					want[k+1000] = len(want)
+				}
+			}
+			checkSorted(t, sm.CollectAll(), want, less)
+		})
+	}
+}
+
 func ExampleSortedMap_All() {
 	sm := NewFromMap(map[string]int{
 		"Bob":     42,
@@ -583,6 +629,45 @@ func TestSortedMap_Insert(t *testing.T) {
 	}
 }
 
+func TestSortedMap_InsertDelete_order(t *testing.T) {
+	sm := New[map[int]int, int, int](func(i, j KV[int, int]) bool {
+		return i.Val < j.Val
+	})
+	want := map[int]int{}
+	for i := 0; i < 500; i++ {
+		key, val := (i*7)%61, (i*13)%97
+		if i%3 == 0 {
+			sm.Delete(key)
+			delete(want, key)
+
+			continue
+		}
+		sm.Insert(key, val)
+		want[key] = val
+	}
+
+	got := sm.CollectAll()
+	if len(got) != len(want) || sm.Len() != len(want) {
+		t.Fatalf("CollectAll() has %d pairs, Len() = %d, want %d", len(got), sm.Len(), len(want))
+	}
+	for i, kv := range got {
+		if want[kv.Key] != kv.Val {
+			t.Errorf("CollectAll()[%d] = %v, want value %v", i, kv, want[kv.Key])
+		}
+		if i > 0 && got[i-1].Val > kv.Val {
+			t.Errorf("CollectAll() is not sorted at %d: %v > %v", i, got[i-1], kv)
+		}
+	}
+	for key := range want {
+		if _, ok := sm.Delete(key); !ok {
+			t.Errorf("Delete(%v) = false, want true", key)
+		}
+	}
+	if sm.Len() != 0 {
+		t.Errorf("Len() = %v, want 0", sm.Len())
+	}
+}
+
 func ExampleSortedMap_Insert() {
 	sm := New[map[string]int, string, int](func(i, j KV[string, int]) bool {
 		return i.Key < j.Key
@@ -854,6 +939,710 @@ func TestSortedMap_Len(t *testing.T) {
 	}
 }
 
+func TestSortedMap_Min(t *testing.T) {
+	type testCase[Map interface{ ~map[K]V }, K comparable, V any] struct {
+		name  string
+		sm    *SortedMap[Map, K, V]
+		want  K
+		want1 V
+		want2 bool
+	}
+	tests := []testCase[map[string]int, string, int]{
+		{
+			name: "empty map",
+			sm: New[map[string]int, string, int](func(i, j KV[string, int]) bool {
+				return i.Key < j.Key
+			}),
+			want:  "",
+			want1: 0,
+			want2: false,
+		},
+		{
+			name: "sorted by key",
+			sm: NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Key < j.Key
+			}),
+			want:  "Alice",
+			want1: 30,
+			want2: true,
+		},
+		{
+			name: "sorted by value, heap storage",
+			sm: NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Val < j.Val
+			}, WithStorage(Heap)),
+			want:  "Charlie",
+			want1: 25,
+			want2: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, got2 := tt.sm.Min()
+			if got != tt.want || got1 != tt.want1 || got2 != tt.want2 {
+				t.Errorf("Min() = %v, %v, %v, want %v, %v, %v", got, got1, got2, tt.want, tt.want1, tt.want2)
+			}
+		})
+	}
+}
+
+func ExampleSortedMap_Min() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(sm.Min())
+	// Output:
+	// Alice 30 true
+}
+
+func TestSortedMap_Max(t *testing.T) {
+	type testCase[Map interface{ ~map[K]V }, K comparable, V any] struct {
+		name  string
+		sm    *SortedMap[Map, K, V]
+		want  K
+		want1 V
+		want2 bool
+	}
+	tests := []testCase[map[string]int, string, int]{
+		{
+			name: "empty map",
+			sm: New[map[string]int, string, int](func(i, j KV[string, int]) bool {
+				return i.Key < j.Key
+			}),
+			want:  "",
+			want1: 0,
+			want2: false,
+		},
+		{
+			name: "sorted by key",
+			sm: NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Key < j.Key
+			}),
+			want:  "Charlie",
+			want1: 25,
+			want2: true,
+		},
+		{
+			name: "sorted by value, heap storage",
+			sm: NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Val < j.Val
+			}, WithStorage(Heap)),
+			want:  "Bob",
+			want1: 42,
+			want2: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, got2 := tt.sm.Max()
+			if got != tt.want || got1 != tt.want1 || got2 != tt.want2 {
+				t.Errorf("Max() = %v, %v, %v, want %v, %v, %v", got, got1, got2, tt.want, tt.want1, tt.want2)
+			}
+		})
+	}
+}
+
+func ExampleSortedMap_Max() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(sm.Max())
+	// Output:
+	// Charlie 25 true
+}
+
+func TestSortedMap_PopMin(t *testing.T) {
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Val < j.Val
+			}, WithStorage(s))
+			var got []string
+			for sm.Len() > 0 {
+				k, _, _ := sm.PopMin()
+				got = append(got, k)
+			}
+			if want := []string{"Charlie", "Alice", "Bob"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("PopMin() = %v, want %v", got, want)
+			}
+			if k, v, ok := sm.PopMin(); ok {
+				t.Errorf("PopMin() = %v, %v, %v, want false", k, v, ok)
+			}
+		})
+	}
+}
+
+func ExampleSortedMap_PopMin() {
+	// tasks are ordered by their deadline
+	tasks := New[map[string]int](func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}, WithStorage(Heap))
+	tasks.Insert("backup", 300)
+	tasks.Insert("report", 100)
+	tasks.Insert("cleanup", 200)
+	tasks.Insert("backup", 50)
+	for tasks.Len() > 0 {
+		fmt.Println(tasks.PopMin())
+	}
+	// Output:
+	// backup 50 true
+	// report 100 true
+	// cleanup 200 true
+}
+
+func TestSortedMap_PopMax(t *testing.T) {
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Val < j.Val
+			}, WithStorage(s))
+			var got []string
+			for sm.Len() > 0 {
+				k, _, _ := sm.PopMax()
+				got = append(got, k)
+			}
+			if want := []string{"Bob", "Alice", "Charlie"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("PopMax() = %v, want %v", got, want)
+			}
+			if k, v, ok := sm.PopMax(); ok {
+				t.Errorf("PopMax() = %v, %v, %v, want false", k, v, ok)
+			}
+		})
+	}
+}
+
+func ExampleSortedMap_PopMax() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(sm.PopMax())
+	fmt.Println(sm.CollectKeys())
+	// Output:
+	// Charlie 25 true
+	// [Alice Bob]
+}
+
+func TestSortedMap_Backward(t *testing.T) {
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[string]int{
+				"Bob":     42,
+				"Alice":   30,
+				"Charlie": 25,
+			}, func(i, j KV[string, int]) bool {
+				return i.Key < j.Key
+			}, WithStorage(s))
+			got := make([]KV[string, int], 0, sm.Len())
+			for k, v := range sm.Backward() {
+				got = append(got, KV[string, int]{k, v})
+			}
+			want := []KV[string, int]{{"Charlie", 25}, {"Bob", 42}, {"Alice", 30}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Backward() = %v, want %v", got, want)
+			}
+			for range sm.Backward() {
+				break
+			}
+			if got := slices.Collect(sm.KeysBackward()); !reflect.DeepEqual(got, []string{"Charlie", "Bob", "Alice"}) {
+				t.Errorf("KeysBackward() = %v, want %v", got, []string{"Charlie", "Bob", "Alice"})
+			}
+			if got := slices.Collect(sm.ValuesBackward()); !reflect.DeepEqual(got, []int{25, 42, 30}) {
+				t.Errorf("ValuesBackward() = %v, want %v", got, []int{25, 42, 30})
+			}
+		})
+	}
+}
+
+func ExampleSortedMap_Backward() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for k, v := range sm.Backward() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// Charlie 25
+	// Bob 42
+	// Alice 30
+}
+
+func ExampleSortedMap_KeysBackward() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for k := range sm.KeysBackward() {
+		fmt.Println(k)
+	}
+	// Output:
+	// Charlie
+	// Bob
+	// Alice
+}
+
+func ExampleSortedMap_ValuesBackward() {
+	sm := NewFromMap(map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for v := range sm.ValuesBackward() {
+		fmt.Println(v)
+	}
+	// Output:
+	// 25
+	// 42
+	// 30
+}
+
+func TestSortedMap_Rank(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		want  int
+		want1 bool
+	}{
+		{name: "first", key: "Eve", want: 0, want1: true},
+		{name: "middle", key: "Alice", want: 2, want1: true},
+		{name: "last", key: "Bob", want: 3, want1: true},
+		{name: "missing", key: "Mallory", want: -1, want1: false},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{
+			"Alice":   30,
+			"Bob":     42,
+			"Charlie": 25,
+			"Eve":     20,
+		}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				got, got1 := sm.Rank(tt.key)
+				if got != tt.want || got1 != tt.want1 {
+					t.Errorf("Rank(%v) = %v, %v, want %v, %v", tt.key, got, got1, tt.want, tt.want1)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_Rank() {
+	// the leaderboard is ordered by score, highest first
+	leaderboard := NewFromMap(map[string]int{
+		"Alice":   1200,
+		"Bob":     900,
+		"Charlie": 1500,
+	}, func(i, j KV[string, int]) bool {
+		return i.Val > j.Val
+	})
+	pos, ok := leaderboard.Rank("Alice")
+	fmt.Println(pos+1, ok)
+	// Output:
+	// 2 true
+}
+
+func TestSortedMap_At(t *testing.T) {
+	tests := []struct {
+		name  string
+		i     int
+		want  string
+		want1 bool
+	}{
+		{name: "first", i: 0, want: "Eve", want1: true},
+		{name: "middle", i: 2, want: "Alice", want1: true},
+		{name: "last", i: 3, want: "Bob", want1: true},
+		{name: "out of range", i: 4, want: "", want1: false},
+		{name: "negative", i: -1, want: "", want1: false},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{
+			"Alice":   30,
+			"Bob":     42,
+			"Charlie": 25,
+			"Eve":     20,
+		}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				got, _, got1 := sm.At(tt.i)
+				if got != tt.want || got1 != tt.want1 {
+					t.Errorf("At(%v) = %v, %v, want %v, %v", tt.i, got, got1, tt.want, tt.want1)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_At() {
+	leaderboard := NewFromMap(map[string]int{
+		"Alice":   1200,
+		"Bob":     900,
+		"Charlie": 1500,
+	}, func(i, j KV[string, int]) bool {
+		return i.Val > j.Val
+	})
+	fmt.Println(leaderboard.At(0))
+	// Output:
+	// Charlie 1500 true
+}
+
+func TestSortedMap_AllFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		i    int
+		want []string
+	}{
+		{name: "from the start", i: 0, want: []string{"Eve", "Charlie", "Alice", "Bob"}},
+		{name: "from the middle", i: 2, want: []string{"Alice", "Bob"}},
+		{name: "past the end", i: 4, want: nil},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{
+			"Alice":   30,
+			"Bob":     42,
+			"Charlie": 25,
+			"Eve":     20,
+		}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				var got []string
+				for k := range sm.AllFrom(tt.i) {
+					got = append(got, k)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("AllFrom(%v) = %v, want %v", tt.i, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_AllFrom() {
+	sm := New[map[int]string](func(i, j KV[int, string]) bool {
+		return i.Key < j.Key
+	})
+	for i := 1; i <= 10; i++ {
+		sm.Insert(i, fmt.Sprintf("item %d", i))
+	}
+
+	const pageSize = 3
+	page := 2
+	n := 0
+	for k, v := range sm.AllFrom((page - 1) * pageSize) {
+		if n++; n > pageSize {
+			break
+		}
+		fmt.Println(k, v)
+	}
+	// Output:
+	// 4 item 4
+	// 5 item 5
+	// 6 item 6
+}
+
+func TestSortedMap_Range(t *testing.T) {
+	type args[K comparable, V any] struct {
+		from, to KV[K, V]
+		opts     []RangeOption
+	}
+	type testCase[Map interface{ ~map[K]V }, K comparable, V any] struct {
+		name string
+		args args[K, V]
+		want []K
+	}
+	tests := []testCase[map[int]int, int, int]{
+		{
+			name: "inclusive bounds",
+			args: args[int, int]{from: KV[int, int]{Key: 2}, to: KV[int, int]{Key: 4}},
+			want: []int{2, 3, 4},
+		},
+		{
+			name: "exclusive lower bound",
+			args: args[int, int]{from: KV[int, int]{Key: 2}, to: KV[int, int]{Key: 4}, opts: []RangeOption{ExcludeFrom()}},
+			want: []int{3, 4},
+		},
+		{
+			name: "exclusive upper bound",
+			args: args[int, int]{from: KV[int, int]{Key: 2}, to: KV[int, int]{Key: 4}, opts: []RangeOption{ExcludeTo()}},
+			want: []int{2, 3},
+		},
+		{
+			name: "exclusive bounds",
+			args: args[int, int]{
+				from: KV[int, int]{Key: 2},
+				to:   KV[int, int]{Key: 4},
+				opts: []RangeOption{ExcludeFrom(), ExcludeTo()},
+			},
+			want: []int{3},
+		},
+		{
+			name: "bounds out of the map",
+			args: args[int, int]{from: KV[int, int]{Key: -10}, to: KV[int, int]{Key: 10}},
+			want: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "bounds between keys",
+			args: args[int, int]{from: KV[int, int]{Key: 10}, to: KV[int, int]{Key: 40}},
+			want: []int{},
+		},
+		{
+			name: "reversed bounds",
+			args: args[int, int]{from: KV[int, int]{Key: 4}, to: KV[int, int]{Key: 2}},
+			want: []int{},
+		},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[int]int{1: 10, 2: 20, 3: 30, 4: 40, 5: 50}, func(i, j KV[int, int]) bool {
+			return i.Key < j.Key
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				got := []int{}
+				for k, v := range sm.Range(tt.args.from, tt.args.to, tt.args.opts...) {
+					if v != k*10 {
+						t.Errorf("Range() yields %v, %v", k, v)
+					}
+					got = append(got, k)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Range(%v, %v) = %v, want %v", tt.args.from, tt.args.to, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_Range() {
+	type Person struct {
+		Name string
+		Age  int
+	}
+	sm := NewFromMap(map[string]Person{
+		"Bob":     {"Bob", 42},
+		"Alice":   {"Alice", 30},
+		"Charlie": {"Charlie", 25},
+		"Eve":     {"Eve", 84},
+	}, func(i, j KV[string, Person]) bool {
+		return i.Val.Age < j.Val.Age
+	})
+	for k, v := range sm.Range(KV[string, Person]{Val: Person{Age: 30}}, KV[string, Person]{Val: Person{Age: 50}}) {
+		fmt.Println(k, v.Age)
+	}
+	// Output:
+	// Alice 30
+	// Bob 42
+}
+
+func TestSortedMap_Floor(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe int
+		want  int
+		want1 bool
+	}{
+		{name: "before the first", probe: 5, want: 0, want1: false},
+		{name: "equal", probe: 20, want: 20, want1: true},
+		{name: "between", probe: 25, want: 20, want1: true},
+		{name: "after the last", probe: 100, want: 40, want1: true},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{"a": 10, "b": 20, "c": 30, "d": 40}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				_, got, got1 := sm.Floor(KV[string, int]{Val: tt.probe})
+				if got != tt.want || got1 != tt.want1 {
+					t.Errorf("Floor(%v) = %v, %v, want %v, %v", tt.probe, got, got1, tt.want, tt.want1)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_Floor() {
+	// readings are bucketed by the minute of a day
+	readings := NewFromMap(map[int]float64{
+		0:   20.5,
+		60:  21.0,
+		120: 23.5,
+	}, func(i, j KV[int, float64]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(readings.Floor(KV[int, float64]{Key: 95}))
+	// Output:
+	// 60 21 true
+}
+
+func TestSortedMap_Ceiling(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe int
+		want  int
+		want1 bool
+	}{
+		{name: "before the first", probe: 5, want: 10, want1: true},
+		{name: "equal", probe: 20, want: 20, want1: true},
+		{name: "between", probe: 25, want: 30, want1: true},
+		{name: "after the last", probe: 100, want: 0, want1: false},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{"a": 10, "b": 20, "c": 30, "d": 40}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				_, got, got1 := sm.Ceiling(KV[string, int]{Val: tt.probe})
+				if got != tt.want || got1 != tt.want1 {
+					t.Errorf("Ceiling(%v) = %v, %v, want %v, %v", tt.probe, got, got1, tt.want, tt.want1)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_Ceiling() {
+	readings := NewFromMap(map[int]float64{
+		0:   20.5,
+		60:  21.0,
+		120: 23.5,
+	}, func(i, j KV[int, float64]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(readings.Ceiling(KV[int, float64]{Key: 95}))
+	// Output:
+	// 120 23.5 true
+}
+
+func TestSortedMap_Lower(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe int
+		want  int
+		want1 bool
+	}{
+		{name: "first", probe: 10, want: 0, want1: false},
+		{name: "equal", probe: 20, want: 10, want1: true},
+		{name: "between", probe: 25, want: 20, want1: true},
+		{name: "after the last", probe: 100, want: 40, want1: true},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{"a": 10, "b": 20, "c": 30, "d": 40}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				_, got, got1 := sm.Lower(KV[string, int]{Val: tt.probe})
+				if got != tt.want || got1 != tt.want1 {
+					t.Errorf("Lower(%v) = %v, %v, want %v, %v", tt.probe, got, got1, tt.want, tt.want1)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_Lower() {
+	readings := NewFromMap(map[int]float64{
+		0:   20.5,
+		60:  21.0,
+		120: 23.5,
+	}, func(i, j KV[int, float64]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(readings.Lower(KV[int, float64]{Key: 60}))
+	// Output:
+	// 0 20.5 true
+}
+
+func TestSortedMap_Higher(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe int
+		want  int
+		want1 bool
+	}{
+		{name: "before the first", probe: 5, want: 10, want1: true},
+		{name: "equal", probe: 20, want: 30, want1: true},
+		{name: "between", probe: 25, want: 30, want1: true},
+		{name: "last", probe: 40, want: 0, want1: false},
+	}
+	for _, s := range storages {
+		sm := NewFromMap(map[string]int{"a": 10, "b": 20, "c": 30, "d": 40}, func(i, j KV[string, int]) bool {
+			return i.Val < j.Val
+		}, WithStorage(s))
+		for _, tt := range tests {
+			t.Run(s.String()+"/"+tt.name, func(t *testing.T) {
+				_, got, got1 := sm.Higher(KV[string, int]{Val: tt.probe})
+				if got != tt.want || got1 != tt.want1 {
+					t.Errorf("Higher(%v) = %v, %v, want %v, %v", tt.probe, got, got1, tt.want, tt.want1)
+				}
+			})
+		}
+	}
+}
+
+func ExampleSortedMap_Higher() {
+	readings := NewFromMap(map[int]float64{
+		0:   20.5,
+		60:  21.0,
+		120: 23.5,
+	}, func(i, j KV[int, float64]) bool {
+		return i.Key < j.Key
+	})
+	fmt.Println(readings.Higher(KV[int, float64]{Key: 60}))
+	// Output:
+	// 120 23.5 true
+}
+
 var benchMap = map[string]int{
 	"Alice":   30,
 	"Bob":     42,
@@ -909,6 +1698,42 @@ func BenchmarkSortedMap_Delete(b *testing.B) {
 	}
 }
 
+func BenchmarkSortedMap_DeleteInsert(b *testing.B) {
+	for _, n := range []int{100, 1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			sm := New[map[int]int, int, int](func(i, j KV[int, int]) bool {
+				return i.Key < j.Key
+			})
+			for i := 0; i < n; i++ {
+				sm.Insert(i, i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := i % n
+				sm.Delete(key)
+				sm.Insert(key, i)
+			}
+		})
+	}
+}
+
+func BenchmarkSortedMap_InsertExisting(b *testing.B) {
+	for _, n := range []int{100, 1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			sm := New[map[int]int, int, int](func(i, j KV[int, int]) bool {
+				return i.Val < j.Val
+			})
+			for i := 0; i < n; i++ {
+				sm.Insert(i, i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sm.Insert(i%n, i)
+			}
+		})
+	}
+}
+
 func BenchmarkSortedMap_All(b *testing.B) {
 	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
 		return i.Key < j.Key
@@ -989,3 +1814,87 @@ func BenchmarkSortedMap_Len(b *testing.B) {
 		sm.Len()
 	}
 }
+
+func BenchmarkSortedMap_Min(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		sm.Min()
+	}
+}
+
+func BenchmarkSortedMap_Max(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		sm.Max()
+	}
+}
+
+func BenchmarkSortedMap_PopMin(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		k, v, _ := sm.PopMin()
+		sm.Insert(k, v)
+	}
+}
+
+func BenchmarkSortedMap_Range(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		for range sm.Range(KV[string, int]{Key: "Frank"}, KV[string, int]{Key: "Judy"}) {
+		}
+	}
+}
+
+func BenchmarkSortedMap_Floor(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		sm.Floor(KV[string, int]{Key: "Irene"})
+	}
+}
+
+func BenchmarkSortedMap_Ceiling(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		sm.Ceiling(KV[string, int]{Key: "Irene"})
+	}
+}
+
+func BenchmarkSortedMap_Backward(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		for range sm.Backward() {
+		}
+	}
+}
+
+func BenchmarkSortedMap_Rank(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		sm.Rank("Judy")
+	}
+}
+
+func BenchmarkSortedMap_At(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := 0; i < b.N; i++ {
+		sm.At(9)
+	}
+}
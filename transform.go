@@ -0,0 +1,66 @@
+package sortedmap
+
+// Filter returns a new SortedMap with the pairs for which pred returns true. It has the comparison function
+// and the options of the map, opts are applied on top of them. It's built from the ordered pairs without
+// comparing them.
+// The complexity is O(n)
+func (sm *SortedMap[Map, K, V]) Filter(pred func(key K, val V) bool, opts ...Option) *SortedMap[Map, K, V] {
+	var pairs []KV[K, V]
+	for k, v := range sm.All() {
+		if pred(k, v) {
+			pairs = append(pairs, KV[K, V]{k, v})
+		}
+	}
+
+	return newLoaded[Map](sm.less, pairs, sm.o.with(opts), true)
+}
+
+// Partition splits the map into a new SortedMap with the pairs for which pred returns true and a new SortedMap
+// with the rest. Both have the comparison function and the options of the map, like with Filter.
+// The complexity is O(n)
+func (sm *SortedMap[Map, K, V]) Partition(
+	pred func(key K, val V) bool,
+	opts ...Option,
+) (matched, rest *SortedMap[Map, K, V]) {
+	o := sm.o.with(opts)
+	var yes, no []KV[K, V]
+	for k, v := range sm.All() {
+		if pred(k, v) {
+			yes = append(yes, KV[K, V]{k, v})
+		} else {
+			no = append(no, KV[K, V]{k, v})
+		}
+	}
+
+	return newLoaded[Map](sm.less, yes, o, true), newLoaded[Map](sm.less, no, o, true)
+}
+
+// MapValues returns a new SortedMap with the values of sm replaced by fn(key, val). Since the type of the values
+// changes, the new map needs its own comparison function `less`. It gets the options of sm, opts are applied
+// on top of them.
+// The complexity is O(n) if the pairs stay in order under `less`, e.g. when the maps are ordered by key,
+// O(n log n) otherwise
+func MapValues[Map ~map[K]V, K comparable, V, W any](
+	sm *SortedMap[Map, K, V],
+	fn func(key K, val V) W,
+	less func(i, j KV[K, W]) bool,
+	opts ...Option,
+) *SortedMap[map[K]W, K, W] {
+	pairs := make([]KV[K, W], 0, sm.Len())
+	for k, v := range sm.All() {
+		pairs = append(pairs, KV[K, W]{k, fn(k, v)})
+	}
+
+	return newLoaded[map[K]W](less, pairs, sm.o.with(opts), false)
+}
+
+// Reduce folds the pairs of the map in order into a single value, starting with init.
+// The complexity is O(n)
+func Reduce[Map ~map[K]V, K comparable, V, A any](sm *SortedMap[Map, K, V], init A, fn func(acc A, key K, val V) A) A {
+	acc := init
+	for k, v := range sm.All() {
+		acc = fn(acc, k, v)
+	}
+
+	return acc
+}
@@ -0,0 +1,183 @@
+package sortedmap
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestSortedMap_Filter(t *testing.T) {
+	byVal := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[string]int{"a": 5, "b": 2, "c": 8, "d": 1}, byVal, WithStorage(s))
+
+			got := sm.Filter(func(_ string, v int) bool { return v > 1 })
+			want := []KV[string, int]{{"b", 2}, {"a", 5}, {"c", 8}}
+			if !slices.Equal(got.CollectAll(), want) {
+				t.Errorf("Filter() = %v, want %v", got.CollectAll(), want)
+			}
+			// the result has the storage of the map unless it's overridden
+			if gt, st := fmt.Sprintf("%T", got.b), fmt.Sprintf("%T", sm.b); gt != st {
+				t.Errorf("Filter() storage = %s, want %s", gt, st)
+			}
+			if _, ok := sm.Filter(func(string, int) bool { return true }, WithStorage(Tree)).b.(*tree[KV[string, int]]); !ok {
+				t.Errorf("Filter() with WithStorage(Tree) has no tree storage")
+			}
+			if sm.Len() != 4 {
+				t.Errorf("Len() = %d, want 4", sm.Len())
+			}
+
+			// the result is a regular map
+			got.Insert("e", 3)
+			checkSorted(t, got.CollectAll(), map[string]int{"a": 5, "b": 2, "c": 8, "e": 3}, byVal)
+
+			if got := sm.Filter(func(string, int) bool { return false }); got.Len() != 0 {
+				t.Errorf("Filter() = %v, want empty", got.CollectAll())
+			}
+		})
+	}
+}
+
+func TestSortedMap_Partition(t *testing.T) {
+	byKey := func(i, j KV[int, string]) bool {
+		return i.Key < j.Key
+	}
+	sm := NewFromMap(map[int]string{1: "one", 2: "two", 3: "three", 4: "four"}, byKey)
+
+	even, odd := sm.Partition(func(k int, _ string) bool { return k%2 == 0 })
+	if want := []KV[int, string]{{2, "two"}, {4, "four"}}; !slices.Equal(even.CollectAll(), want) {
+		t.Errorf("Partition() matched = %v, want %v", even.CollectAll(), want)
+	}
+	if want := []KV[int, string]{{1, "one"}, {3, "three"}}; !slices.Equal(odd.CollectAll(), want) {
+		t.Errorf("Partition() rest = %v, want %v", odd.CollectAll(), want)
+	}
+}
+
+func TestSortedMap_Partition_options(t *testing.T) {
+	sm := NewFromMap(map[int]string{1: "one", 2: "two"}, func(i, j KV[int, string]) bool {
+		return i.Key < j.Key
+	}, WithStorage(SortedSlice), WithJSONPairs())
+
+	even, odd := sm.Partition(func(k int, _ string) bool { return k%2 == 0 })
+	for _, m := range []*SortedMap[map[int]string, int, string]{even, odd} {
+		if _, ok := m.b.(*sortedSlice[KV[int, string]]); !ok {
+			t.Errorf("Partition() storage = %T, want SortedSlice", m.b)
+		}
+		data, err := m.MarshalJSON()
+		if err != nil || data[0] != '[' {
+			t.Errorf("MarshalJSON() = %s, %v, want an array of pairs", data, err)
+		}
+	}
+
+	labels := MapValues(sm, func(_ int, v string) int { return len(v) }, func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+	if _, ok := labels.b.(*sortedSlice[KV[int, int]]); !ok {
+		t.Errorf("MapValues() storage = %T, want SortedSlice", labels.b)
+	}
+}
+
+func TestMapValues(t *testing.T) {
+	sm := NewFromMap(map[string]int{"a": 3, "b": 1, "c": 2}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	tests := []struct {
+		name string
+		less func(i, j KV[string, string]) bool
+		want []KV[string, string]
+	}{
+		{
+			name: "same order",
+			less: func(i, j KV[string, string]) bool {
+				return i.Key < j.Key
+			},
+			want: []KV[string, string]{{"a", "3"}, {"b", "1"}, {"c", "2"}},
+		},
+		{
+			name: "new order",
+			less: func(i, j KV[string, string]) bool {
+				return i.Val > j.Val
+			},
+			want: []KV[string, string]{{"a", "3"}, {"c", "2"}, {"b", "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MapValues(sm, func(_ string, v int) string { return strconv.Itoa(v) }, tt.less)
+			if !slices.Equal(got.CollectAll(), tt.want) {
+				t.Errorf("MapValues() = %v, want %v", got.CollectAll(), tt.want)
+			}
+			if v, ok := got.Get("b"); !ok || v != "1" {
+				t.Errorf("Get() = %v, %v, want 1, true", v, ok)
+			}
+		})
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sm := NewFromMap(map[string]int{"c": 3, "a": 1, "b": 2}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	got := Reduce(sm, "", func(acc, k string, v int) string {
+		return acc + k + strconv.Itoa(v)
+	})
+	if got != "a1b2c3" {
+		t.Errorf("Reduce() = %q, want %q", got, "a1b2c3")
+	}
+
+	empty := New[map[string]int](sm.less)
+	if got := Reduce(empty, 42, func(acc int, _ string, v int) int { return acc + v }); got != 42 {
+		t.Errorf("Reduce() = %d, want 42", got)
+	}
+}
+
+func ExampleMapValues() {
+	prices := NewFromMap(map[string]int{"tea": 250, "coffee": 300, "water": 100}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	labels := MapValues(prices, func(_ string, cents int) string {
+		return fmt.Sprintf("$%d.%02d", cents/100, cents%100)
+	}, func(i, j KV[string, string]) bool {
+		return i.Key < j.Key
+	})
+	for k, v := range labels.All() {
+		fmt.Println(k, v)
+	}
+	// Output:
+	// coffee $3.00
+	// tea $2.50
+	// water $1.00
+}
+
+func ExampleReduce() {
+	sm := NewFromMap(map[string]int{"a": 1, "b": 2, "c": 3}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	total := Reduce(sm, 0, func(acc int, _ string, v int) int {
+		return acc + v
+	})
+	fmt.Println(total)
+	// Output: 6
+}
+
+func BenchmarkSortedMap_Filter(b *testing.B) {
+	sm := New[map[int]int](func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+	for i := range 10_000 {
+		sm.Insert(i, i)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		sm.Filter(func(k, _ int) bool { return k%2 == 0 })
+	}
+}
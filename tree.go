@@ -0,0 +1,446 @@
+package sortedmap
+
+import "iter"
+
+// tree is an AVL tree. Items which are equal according to less are allowed and are kept in insertion order,
+// same tells them apart when a particular item has to be removed. Every node knows the size of its subtree,
+// so items can be found by their position.
+//
+// Nodes can be shared between trees after snapshot. A tree changes only the nodes it owns and copies the others,
+// so a change never shows up in another tree.
+type tree[T any] struct {
+	root  *node[T]
+	len   int
+	less  func(a, b T) bool
+	same  func(a, b T) bool
+	owner *treeOwner
+}
+
+type node[T any] struct {
+	item        T
+	left, right *node[T]
+	height      int
+	size        int
+	owner       *treeOwner
+}
+
+// treeOwner tells apart the nodes created by different trees. It's not empty,
+// so every instance has its own address.
+type treeOwner struct{ _ byte }
+
+func newTree[T any](less, same func(a, b T) bool) *tree[T] {
+	return &tree[T]{
+		less:  less,
+		same:  same,
+		owner: &treeOwner{},
+	}
+}
+
+// snapshot returns a copy of the tree which shares all the nodes with it. After that, both trees copy a node
+// before changing it, so they don't see each other's changes.
+// The complexity is O(1)
+func (t *tree[T]) snapshot() *tree[T] {
+	t.owner = &treeOwner{}
+
+	return t.clone()
+}
+
+// clone returns a copy of the tree which shares all the nodes with it and copies a node before changing it.
+// The tree itself keeps changing its own nodes in place, so it must not be changed after that.
+// The complexity is O(1)
+func (t *tree[T]) clone() *tree[T] {
+	c := *t
+	c.owner = &treeOwner{}
+
+	return &c
+}
+
+// Len returns the number of items in the tree.
+func (t *tree[T]) Len() int {
+	return t.len
+}
+
+// Insert adds the item to the tree.
+// The complexity is O(log n)
+func (t *tree[T]) Insert(item T) {
+	t.root = t.insertAt(t.root, item)
+	t.len++
+}
+
+// load replaces the content of the tree with the items, which have to be in ascending order.
+// The complexity is O(n)
+func (t *tree[T]) load(items []T) {
+	t.root = t.build(items)
+	t.len = len(items)
+}
+
+// build returns a perfectly balanced subtree of the sorted items.
+func (t *tree[T]) build(items []T) *node[T] {
+	if len(items) == 0 {
+		return nil
+	}
+	mid := len(items) / 2
+	n := &node[T]{item: items[mid], owner: t.owner}
+	n.left, n.right = t.build(items[:mid]), t.build(items[mid+1:])
+	n.update()
+
+	return n
+}
+
+// Delete removes the item which is the same as the given one and reports whether it was found.
+// The complexity is O(log n) plus the number of items equal to the given one.
+func (t *tree[T]) Delete(item T) bool {
+	var found bool
+	t.root, found = t.removeAt(t.root, item)
+	if found {
+		t.len--
+	}
+
+	return found
+}
+
+// All returns a sequence of the items in ascending order.
+// The complexity is O(n) for the whole traversal.
+func (t *tree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stack := t.stack()
+		for n := t.root; n != nil; n = n.left {
+			stack = append(stack, n)
+		}
+		walk(stack, yield)
+	}
+}
+
+// Backward returns a sequence of the items in descending order.
+// The complexity is O(n) for the whole traversal.
+func (t *tree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stack := t.stack()
+		for n := t.root; n != nil; n = n.right {
+			stack = append(stack, n)
+		}
+		walkBackward(stack, yield)
+	}
+}
+
+// Seek returns a sequence of the items in ascending order, starting from the first one which is not less than probe.
+// The complexity is O(log n) to find the first item.
+func (t *tree[T]) Seek(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stack := t.stack()
+		for n := t.root; n != nil; {
+			if t.less(n.item, probe) {
+				n = n.right
+			} else {
+				stack = append(stack, n)
+				n = n.left
+			}
+		}
+		walk(stack, yield)
+	}
+}
+
+// SeekBackward returns a sequence of the items in descending order, starting from the last one which is not
+// greater than probe.
+// The complexity is O(log n) to find the first item.
+func (t *tree[T]) SeekBackward(probe T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stack := t.stack()
+		for n := t.root; n != nil; {
+			if t.less(probe, n.item) {
+				n = n.left
+			} else {
+				stack = append(stack, n)
+				n = n.right
+			}
+		}
+		walkBackward(stack, yield)
+	}
+}
+
+// AllFrom returns a sequence of the items in ascending order, starting from the i-th one.
+// The complexity is O(log n) to find the first item.
+func (t *tree[T]) AllFrom(i int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stack := t.stack()
+		i := max(i, 0)
+		for n := t.root; n != nil; {
+			switch l := size(n.left); {
+			case i < l:
+				stack = append(stack, n)
+				n = n.left
+			case i == l:
+				stack = append(stack, n)
+				n = nil
+			default:
+				i -= l + 1
+				n = n.right
+			}
+		}
+		walk(stack, yield)
+	}
+}
+
+// Rank returns the position of the item which is the same as the given one, or -1 if there is no such item.
+// The complexity is O(log n) plus the number of items equal to the given one.
+func (t *tree[T]) Rank(item T) int {
+	return t.rankAt(t.root, item)
+}
+
+// At returns the i-th item and false if there is no such item.
+// The complexity is O(log n)
+func (t *tree[T]) At(i int) (T, bool) {
+	n := t.root
+	for n != nil {
+		switch l := size(n.left); {
+		case i < l:
+			n = n.left
+		case i == l:
+			return n.item, true
+		default:
+			i -= l + 1
+			n = n.right
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (t *tree[T]) rankAt(n *node[T], item T) int {
+	if n == nil {
+		return -1
+	}
+
+	switch {
+	case t.less(item, n.item):
+		return t.rankAt(n.left, item)
+	case t.less(n.item, item):
+	case t.same(item, n.item):
+		return size(n.left)
+	default:
+		// the item is equal to n.item, rotations may have moved it to any side
+		if r := t.rankAt(n.left, item); r >= 0 {
+			return r
+		}
+	}
+	if r := t.rankAt(n.right, item); r >= 0 {
+		return size(n.left) + 1 + r
+	}
+
+	return -1
+}
+
+// Min returns the first item and false if the tree is empty.
+// The complexity is O(log n)
+func (t *tree[T]) Min() (T, bool) {
+	n := t.root
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+
+	return n.item, true
+}
+
+// Max returns the last item and false if the tree is empty.
+// The complexity is O(log n)
+func (t *tree[T]) Max() (T, bool) {
+	n := t.root
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	for n.right != nil {
+		n = n.right
+	}
+
+	return n.item, true
+}
+
+func (t *tree[T]) stack() []*node[T] {
+	return make([]*node[T], 0, height(t.root))
+}
+
+// walk continues an in-order traversal. The top of the stack is the next node to visit,
+// and each node in the stack is followed by its right subtree.
+func walk[T any](stack []*node[T], yield func(T) bool) {
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !yield(n.item) {
+			return
+		}
+		for c := n.right; c != nil; c = c.left {
+			stack = append(stack, c)
+		}
+	}
+}
+
+// walkBackward continues a reverse in-order traversal. The top of the stack is the next node to visit,
+// and each node in the stack is followed by its left subtree.
+func walkBackward[T any](stack []*node[T], yield func(T) bool) {
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !yield(n.item) {
+			return
+		}
+		for c := n.left; c != nil; c = c.right {
+			stack = append(stack, c)
+		}
+	}
+}
+
+// mut returns n if the tree owns it, or a copy of n owned by the tree.
+func (t *tree[T]) mut(n *node[T]) *node[T] {
+	if n.owner == t.owner {
+		return n
+	}
+	c := *n
+	c.owner = t.owner
+
+	return &c
+}
+
+func (t *tree[T]) insertAt(n *node[T], item T) *node[T] {
+	if n == nil {
+		return &node[T]{item: item, height: 1, size: 1, owner: t.owner}
+	}
+	n = t.mut(n)
+	if t.less(item, n.item) {
+		n.left = t.insertAt(n.left, item)
+	} else {
+		n.right = t.insertAt(n.right, item)
+	}
+
+	return t.rebalance(n)
+}
+
+func (t *tree[T]) removeAt(n *node[T], item T) (*node[T], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var (
+		c     *node[T]
+		found bool
+		left  bool
+	)
+	switch {
+	case t.less(item, n.item):
+		c, found = t.removeAt(n.left, item)
+		left = true
+	case t.less(n.item, item):
+		c, found = t.removeAt(n.right, item)
+	case t.same(item, n.item):
+		return t.removeNode(n), true
+	default:
+		// the item is equal to n.item, rotations may have moved it to any side
+		if c, found = t.removeAt(n.left, item); found {
+			left = true
+		} else {
+			c, found = t.removeAt(n.right, item)
+		}
+	}
+	if !found {
+		return n, false
+	}
+	n = t.mut(n)
+	if left {
+		n.left = c
+	} else {
+		n.right = c
+	}
+
+	return t.rebalance(n), true
+}
+
+// removeNode unlinks n from the tree and returns the root of the subtree which takes its place.
+func (t *tree[T]) removeNode(n *node[T]) *node[T] {
+	switch {
+	case n.left == nil:
+		return n.right
+	case n.right == nil:
+		return n.left
+	}
+	n = t.mut(n)
+	n.right, n.item = t.removeMin(n.right)
+
+	return t.rebalance(n)
+}
+
+// removeMin unlinks the leftmost node of the subtree and returns the new subtree root and the removed item.
+func (t *tree[T]) removeMin(n *node[T]) (*node[T], T) {
+	if n.left == nil {
+		return n.right, n.item
+	}
+	n = t.mut(n)
+	var item T
+	n.left, item = t.removeMin(n.left)
+
+	return t.rebalance(n), item
+}
+
+func height[T any](n *node[T]) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.height
+}
+
+func size[T any](n *node[T]) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
+}
+
+func (n *node[T]) update() {
+	n.height = 1 + max(height(n.left), height(n.right))
+	n.size = 1 + size(n.left) + size(n.right)
+}
+
+// rebalance restores the balance of n, which has to be owned by the tree, and returns the new subtree root.
+func (t *tree[T]) rebalance(n *node[T]) *node[T] {
+	n.update()
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = t.rotateLeft(t.mut(n.left))
+		}
+
+		return t.rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = t.rotateRight(t.mut(n.right))
+		}
+
+		return t.rotateLeft(n)
+	}
+
+	return n
+}
+
+func (t *tree[T]) rotateLeft(n *node[T]) *node[T] {
+	r := t.mut(n.right)
+	n.right, r.left = r.left, n
+	n.update()
+	r.update()
+
+	return r
+}
+
+func (t *tree[T]) rotateRight(n *node[T]) *node[T] {
+	l := t.mut(n.left)
+	n.left, l.right = l.right, n
+	n.update()
+	l.update()
+
+	return l
+}
@@ -0,0 +1,132 @@
+package sortedmap
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkTree verifies the AVL invariants and returns the height of the subtree.
+func checkTree[T any](t *testing.T, tr *tree[T], n *node[T]) int {
+	t.Helper()
+	if n == nil {
+		return 0
+	}
+	l, r := checkTree(t, tr, n.left), checkTree(t, tr, n.right)
+	if n.left != nil && tr.less(n.item, n.left.item) {
+		t.Fatalf("left child %v is greater than its parent %v", n.left.item, n.item)
+	}
+	if n.right != nil && tr.less(n.right.item, n.item) {
+		t.Fatalf("right child %v is less than its parent %v", n.right.item, n.item)
+	}
+	if l-r > 1 || r-l > 1 {
+		t.Fatalf("node %v is unbalanced: %d vs %d", n.item, l, r)
+	}
+	if n.height != 1+max(l, r) {
+		t.Fatalf("node %v has height %d, want %d", n.item, n.height, 1+max(l, r))
+	}
+	if n.size != 1+size(n.left)+size(n.right) {
+		t.Fatalf("node %v has size %d, want %d", n.item, n.size, 1+size(n.left)+size(n.right))
+	}
+
+	return n.height
+}
+
+func TestTree(t *testing.T) {
+	tests := []struct {
+		name string
+		less func(a, b int) bool
+	}{
+		{
+			name: "unique items",
+			less: func(a, b int) bool { return a < b },
+		},
+		{
+			name: "equal items",
+			less: func(a, b int) bool { return a/10 < b/10 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(42))
+			tr := newTree(tt.less, func(a, b int) bool { return a == b })
+			var want []int
+			for i := 0; i < 2000; i++ {
+				x := rnd.Intn(300)
+				if j := slices.Index(want, x); j >= 0 {
+					if !tr.Delete(x) {
+						t.Fatalf("remove(%d) = false, want true", x)
+					}
+					want = slices.Delete(want, j, j+1)
+				} else {
+					tr.Insert(x)
+					want = append(want, x)
+				}
+				checkTree(t, tr, tr.root)
+			}
+			if tr.Delete(-1) {
+				t.Errorf("remove(-1) = true, want false")
+			}
+
+			slices.SortStableFunc(want, func(a, b int) int {
+				switch {
+				case tt.less(a, b):
+					return -1
+				case tt.less(b, a):
+					return 1
+				}
+				return 0
+			})
+			got := slices.Collect(tr.All())
+			if tr.len != len(want) {
+				t.Errorf("len = %d, want %d", tr.len, len(want))
+			}
+			for i := range got {
+				if tt.less(got[i], want[i]) || tt.less(want[i], got[i]) {
+					t.Fatalf("ascend() = %v, want %v", got, want)
+				}
+			}
+			if !slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(want))) {
+				t.Errorf("ascend() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTree_snapshot(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	tr := newTree(func(a, b int) bool { return a/10 < b/10 }, func(a, b int) bool { return a == b })
+	for i := 0; i < 500; i++ {
+		tr.Insert(rnd.Intn(1000))
+	}
+
+	var snaps []*tree[int]
+	var wants [][]int
+	for i := 0; i < 20; i++ {
+		snaps = append(snaps, tr.snapshot())
+		wants = append(wants, slices.Collect(tr.All()))
+		for j := 0; j < 50; j++ {
+			if x, ok := tr.At(rnd.Intn(tr.Len())); ok && j%2 == 0 {
+				tr.Delete(x)
+			} else {
+				tr.Insert(rnd.Intn(1000))
+			}
+		}
+		// a snapshot can be changed too, without touching the tree
+		if i%5 == 0 {
+			s := tr.snapshot()
+			for j := 0; j < 50; j++ {
+				s.Insert(rnd.Intn(1000))
+			}
+			checkTree(t, s, s.root)
+		}
+		checkTree(t, tr, tr.root)
+	}
+
+	for i, s := range snaps {
+		checkTree(t, s, s.root)
+		if got := slices.Collect(s.All()); !slices.Equal(got, wants[i]) {
+			t.Fatalf("snapshot %d has changed: %v, want %v", i, got, wants[i])
+		}
+	}
+}
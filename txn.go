@@ -0,0 +1,111 @@
+package sortedmap
+
+import "errors"
+
+// ErrTxnDone is returned by Commit and Rollback of a transaction which has already been committed or rolled back.
+var ErrTxnDone = errors.New("sortedmap: transaction has already been committed or rolled back")
+
+// Txn is a batch of changes of a SortedMap which are applied all together by Commit or dropped by Rollback.
+//
+// The changes are kept aside until Commit, so the map stays exactly as it was while the transaction is open
+// and after Rollback. The transaction sees its own changes on top of the map. It's not isolated from the changes
+// made to the map directly while it's open: those are visible to it, and its own changes win on Commit.
+type Txn[Map ~map[K]V, K comparable, V any] struct {
+	sm *SortedMap[Map, K, V]
+	// writes holds the pending changes by key, in the order they were first made
+	writes map[K]int
+	log    []txnWrite[K, V]
+	done   bool
+}
+
+type txnWrite[K comparable, V any] struct {
+	kv      KV[K, V]
+	deleted bool
+}
+
+// Begin starts a new transaction on the map
+// The complexity is O(1)
+func (sm *SortedMap[Map, K, V]) Begin() *Txn[Map, K, V] {
+	return &Txn[Map, K, V]{
+		sm:     sm,
+		writes: map[K]int{},
+	}
+}
+
+// Get returns the value associated with the key and a boolean indicating if the key exists,
+// taking the changes of the transaction into account
+// The complexity is O(1)
+func (tx *Txn[Map, K, V]) Get(key K) (V, bool) {
+	if i, ok := tx.writes[key]; ok {
+		w := tx.log[i]
+		if w.deleted {
+			return *new(V), false
+		}
+
+		return w.kv.Val, true
+	}
+
+	return tx.sm.Get(key)
+}
+
+// Insert adds a key-value pair to the transaction. If the key already exists, the value is updated on Commit
+// The complexity is O(1)
+func (tx *Txn[Map, K, V]) Insert(key K, val V) {
+	tx.write(txnWrite[K, V]{kv: KV[K, V]{key, val}})
+}
+
+// Delete removes the key in the transaction and returns the value associated with the key and a boolean
+// indicating if the key existed
+// The complexity is O(1)
+func (tx *Txn[Map, K, V]) Delete(key K) (val *V, existed bool) {
+	v, ok := tx.Get(key)
+	if !ok {
+		return (*V)(nil), false
+	}
+	tx.write(txnWrite[K, V]{kv: KV[K, V]{Key: key}, deleted: true})
+
+	return &v, true
+}
+
+// Commit applies all the changes of the transaction to the map
+// The complexity is O(k log n) where k is the number of the changed keys
+func (tx *Txn[Map, K, V]) Commit() error {
+	if tx.done {
+		return ErrTxnDone
+	}
+	tx.done = true
+	for _, w := range tx.log {
+		if w.deleted {
+			tx.sm.Delete(w.kv.Key)
+		} else {
+			tx.sm.Insert(w.kv.Key, w.kv.Val)
+		}
+	}
+	tx.writes, tx.log = nil, nil
+
+	return nil
+}
+
+// Rollback drops all the changes of the transaction, the map is left as it was
+// The complexity is O(1)
+func (tx *Txn[Map, K, V]) Rollback() error {
+	if tx.done {
+		return ErrTxnDone
+	}
+	tx.done = true
+	tx.writes, tx.log = nil, nil
+
+	return nil
+}
+
+func (tx *Txn[Map, K, V]) write(w txnWrite[K, V]) {
+	if tx.done {
+		panic(ErrTxnDone)
+	}
+	if i, ok := tx.writes[w.kv.Key]; ok {
+		tx.log[i] = w
+		return
+	}
+	tx.writes[w.kv.Key] = len(tx.log)
+	tx.log = append(tx.log, w)
+}
@@ -0,0 +1,175 @@
+package sortedmap
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestTxn(t *testing.T) {
+	less := func(i, j KV[string, int]) bool {
+		return i.Val < j.Val
+	}
+	data := map[string]int{
+		"Bob":     42,
+		"Alice":   30,
+		"Charlie": 25,
+	}
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(data, less, WithStorage(s))
+			want := NewFromMap(data, less, WithStorage(s))
+			before := slices.Collect(sm.b.All())
+
+			tx := sm.Begin()
+			tx.Insert("Eve", 20)
+			want.Insert("Eve", 20)
+			tx.Insert("Bob", 10)
+			want.Insert("Bob", 10)
+			if v, ok := tx.Delete("Alice"); !ok || *v != 30 {
+				t.Errorf("Delete(Alice) = %v, %v, want 30, true", ptrVal(v), ok)
+			}
+			want.Delete("Alice")
+			if v, ok := tx.Delete("Alice"); ok {
+				t.Errorf("Delete(Alice) = %v, true, want false", ptrVal(v))
+			}
+			if v, ok := tx.Delete("Eve"); !ok || *v != 20 {
+				t.Errorf("Delete(Eve) = %v, %v, want 20, true", ptrVal(v), ok)
+			}
+			want.Delete("Eve")
+			tx.Insert("Alice", 50)
+			want.Insert("Alice", 50)
+
+			tests := []struct {
+				key     string
+				wantVal int
+				wantOk  bool
+			}{
+				{"Alice", 50, true},
+				{"Bob", 10, true},
+				{"Charlie", 25, true},
+				{"Eve", 0, false},
+			}
+			for _, tt := range tests {
+				if v, ok := tx.Get(tt.key); v != tt.wantVal || ok != tt.wantOk {
+					t.Errorf("Get(%v) = %v, %v, want %v, %v", tt.key, v, ok, tt.wantVal, tt.wantOk)
+				}
+			}
+			// nothing is visible in the map before Commit
+			if got := slices.Collect(sm.b.All()); !slices.Equal(got, before) || !maps.Equal(sm.m, data) {
+				t.Fatalf("the map has changed before Commit: %v", got)
+			}
+
+			if err := tx.Commit(); err != nil {
+				t.Fatalf("Commit() = %v, want nil", err)
+			}
+			if got, want := sm.CollectAll(), want.CollectAll(); !slices.Equal(got, want) {
+				t.Errorf("CollectAll() = %v, want %v", got, want)
+			}
+			if got := sm.Len(); got != 3 {
+				t.Errorf("Len() = %d, want 3", got)
+			}
+		})
+	}
+}
+
+func TestTxn_Rollback(t *testing.T) {
+	for _, s := range storages {
+		t.Run(s.String(), func(t *testing.T) {
+			sm := NewFromMap(map[int]int{1: 1, 2: 2, 3: 3}, func(i, j KV[int, int]) bool {
+				return i.Key < j.Key
+			}, WithStorage(s))
+			before, beforeMap := slices.Collect(sm.b.All()), maps.Clone(sm.m)
+
+			tx := sm.Begin()
+			for i := 0; i < 10; i++ {
+				tx.Insert(i, -i)
+			}
+			tx.Delete(2)
+			if err := tx.Rollback(); err != nil {
+				t.Fatalf("Rollback() = %v, want nil", err)
+			}
+
+			if got := slices.Collect(sm.b.All()); !slices.Equal(got, before) {
+				t.Errorf("backend = %v, want %v", got, before)
+			}
+			if !maps.Equal(sm.m, beforeMap) {
+				t.Errorf("map = %v, want %v", sm.m, beforeMap)
+			}
+		})
+	}
+}
+
+func TestTxn_done(t *testing.T) {
+	sm := New[map[int]int](func(i, j KV[int, int]) bool {
+		return i.Key < j.Key
+	})
+
+	tx := sm.Begin()
+	tx.Insert(1, 1)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want nil", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxnDone) {
+		t.Errorf("Commit() = %v, want %v", err, ErrTxnDone)
+	}
+	if err := tx.Rollback(); !errors.Is(err, ErrTxnDone) {
+		t.Errorf("Rollback() = %v, want %v", err, ErrTxnDone)
+	}
+	panicsWithValue(t, ErrTxnDone, func() {
+		tx.Insert(2, 2)
+	})
+	panicsWithValue(t, ErrTxnDone, func() {
+		tx.Delete(1)
+	})
+	if got := sm.CollectKeys(); !slices.Equal(got, []int{1}) {
+		t.Errorf("CollectKeys() = %v, want [1]", got)
+	}
+}
+
+func ExampleSortedMap_Begin() {
+	sm := NewFromMap(map[string]int{
+		"Alice": 100,
+		"Bob":   50,
+	}, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+
+	transfer := func(from, to string, amount int) error {
+		tx := sm.Begin()
+		balance, _ := tx.Get(from)
+		tx.Insert(from, balance-amount)
+		if balance < amount {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s has only %d", from, balance)
+		}
+		got, _ := tx.Get(to)
+		tx.Insert(to, got+amount)
+
+		return tx.Commit()
+	}
+
+	fmt.Println(transfer("Alice", "Bob", 70))
+	fmt.Println(transfer("Alice", "Bob", 70))
+	fmt.Println(sm.Collect())
+	// Output:
+	// <nil>
+	// Alice has only 30
+	// map[Alice:30 Bob:120]
+}
+
+func BenchmarkTxn_Commit(b *testing.B) {
+	sm := NewFromMap(benchMap, func(i, j KV[string, int]) bool {
+		return i.Key < j.Key
+	})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx := sm.Begin()
+		tx.Insert("Alice", i)
+		tx.Insert("Roger", i)
+		tx.Delete("Bob")
+		_ = tx.Commit()
+	}
+}